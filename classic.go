@@ -1,6 +1,7 @@
 package uconfig
 
 import (
+	"github.com/omeid/uconfig/flat"
 	"github.com/omeid/uconfig/plugins"
 	"github.com/omeid/uconfig/plugins/defaults"
 	"github.com/omeid/uconfig/plugins/env"
@@ -34,3 +35,10 @@ func Classic[C any](files PluginProvider, userPlugins ...plugins.Plugin) Config[
 
 	return New[C](ps...)
 }
+
+// FlagNaming returns a plugin that names the flags of the fields
+// without an explicit flag tag, like flag.WithNaming, for the flag
+// plugin of Classic.
+func FlagNaming(naming flat.Naming) plugins.Plugin {
+	return extend(func(n flag.Namer) { n.SetNaming(naming) })
+}
@@ -0,0 +1,159 @@
+// Command uconfig-sample writes an annotated sample config file for a
+// config struct, see uconfig.Sample.
+//
+// It must be run from within the module of the config struct, e.g.
+//
+//	go run github.com/omeid/uconfig/cmd/uconfig-sample -format=yaml ./config.Config > config.sample.yaml
+package main
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"text/template"
+)
+
+func main() {
+	format := flag.String("format", "yaml", "the sample format: yaml, toml, json, env, or flat")
+	output := flag.String("o", "", "the file to write the sample to, defaults to stdout")
+
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "Usage:\n\t%s [flags] package.Type\n\nFlags:\n", filepath.Base(os.Args[0]))
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	if flag.NArg() != 1 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	err := run(flag.Arg(0), *format, *output)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "uconfig-sample:", err)
+		os.Exit(1)
+	}
+}
+
+func run(target string, format string, output string) error {
+	pkg, typ, err := splitTarget(target)
+	if err != nil {
+		return err
+	}
+
+	importPath, err := goList(pkg)
+	if err != nil {
+		return err
+	}
+
+	src, err := program(importPath, typ, format)
+	if err != nil {
+		return err
+	}
+
+	// the program has to be within the module to import the package,
+	// go ignores directories starting with _ for patterns like ./...
+	dir, err := os.MkdirTemp(".", "_uconfig-sample")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(dir) //nolint:errcheck // best effort
+
+	err = os.WriteFile(filepath.Join(dir, "main.go"), src, 0o600)
+	if err != nil {
+		return err
+	}
+
+	// the sample is buffered so that a failing program
+	// leaves no partial output behind.
+	var out bytes.Buffer
+
+	cmd := exec.Command("go", "run", "./"+filepath.ToSlash(dir))
+	cmd.Stdout = &out
+	cmd.Stderr = os.Stderr
+
+	err = cmd.Run()
+	if err != nil {
+		return err
+	}
+
+	if output == "" {
+		_, err = out.WriteTo(os.Stdout)
+		return err
+	}
+
+	return os.WriteFile(output, out.Bytes(), 0o644)
+}
+
+// splitTarget splits package.Type into the package and the type.
+func splitTarget(target string) (string, string, error) {
+	i := strings.LastIndex(target, ".")
+	if i < 0 || i < strings.LastIndex(target, "/") || i == len(target)-1 {
+		return "", "", fmt.Errorf("expected package.Type, got %q", target)
+	}
+
+	pkg, typ := target[:i], target[i+1:]
+	if pkg == "" {
+		pkg = "."
+	}
+
+	return pkg, typ, nil
+}
+
+// goList resolves the package to its import path.
+func goList(pkg string) (string, error) {
+	var stderr bytes.Buffer
+
+	cmd := exec.Command("go", "list", "-f", "{{.ImportPath}} {{.Name}}", pkg)
+	cmd.Stderr = &stderr
+
+	out, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("%w: %s", err, strings.TrimSpace(stderr.String()))
+	}
+
+	importPath, name, _ := strings.Cut(strings.TrimSpace(string(out)), " ")
+	if name == "main" {
+		return "", errors.New("the config struct can't be in package main, as it can't be imported")
+	}
+
+	return importPath, nil
+}
+
+var programTemplate = template.Must(template.New("main").Parse(`package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/omeid/uconfig"
+
+	target {{ printf "%q" .Import }}
+)
+
+func main() {
+	err := uconfig.Sample[target.{{ .Type }}](os.Stdout, uconfig.Format({{ printf "%q" .Format }}))
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+`))
+
+// program returns the source of a program that writes the sample.
+func program(importPath string, typ string, format string) ([]byte, error) {
+	var buf bytes.Buffer
+
+	err := programTemplate.Execute(&buf, struct {
+		Import string
+		Type   string
+		Format string
+	}{importPath, typ, format})
+
+	return buf.Bytes(), err
+}
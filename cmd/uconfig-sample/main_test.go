@@ -0,0 +1,87 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestSplitTarget(t *testing.T) {
+	tests := []struct {
+		target string
+		pkg    string
+		typ    string
+		err    bool
+	}{
+		{target: "./config.Config", pkg: "./config", typ: "Config"},
+		{target: ".Config", pkg: ".", typ: "Config"},
+		{target: "github.com/omeid/uconfig/internal/f.Config", pkg: "github.com/omeid/uconfig/internal/f", typ: "Config"},
+		{target: "./config", err: true},
+		{target: "github.com/omeid/uconfig", err: true},
+		{target: "./config.", err: true},
+	}
+
+	for _, tt := range tests {
+		pkg, typ, err := splitTarget(tt.target)
+		if tt.err {
+			if err == nil {
+				t.Errorf("%s: expected error", tt.target)
+			}
+			continue
+		}
+
+		if err != nil {
+			t.Errorf("%s: %v", tt.target, err)
+			continue
+		}
+
+		if pkg != tt.pkg || typ != tt.typ {
+			t.Errorf("%s: expected %s %s, got %s %s", tt.target, tt.pkg, tt.typ, pkg, typ)
+		}
+	}
+}
+
+func TestRun(t *testing.T) {
+	if testing.Short() {
+		t.Skip("runs the go tool")
+	}
+
+	output := filepath.Join(t.TempDir(), "sample.env")
+
+	err := run("github.com/omeid/uconfig/internal/f.Config", "env", output)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sample, err := os.ReadFile(output)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, expect := range []string{
+		"# main database used by our application\n# flag -rethink-db, env RETHINK_DB\nRETHINK_DB=primary\n",
+		"RETHINK_PASSWORD=<secret>\n",
+	} {
+		if !strings.Contains(string(sample), expect) {
+			t.Errorf("expected %q in:\n%s", expect, sample)
+		}
+	}
+}
+
+func TestRunFailure(t *testing.T) {
+	if testing.Short() {
+		t.Skip("runs the go tool")
+	}
+
+	output := filepath.Join(t.TempDir(), "sample.xml")
+
+	err := run("github.com/omeid/uconfig/internal/f.Config", "xml", output)
+	if err == nil {
+		t.Fatal("expected the unsupported format to fail")
+	}
+
+	if _, err := os.Stat(output); !os.IsNotExist(err) {
+		t.Errorf("expected no output file, got %v", err)
+	}
+}
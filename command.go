@@ -0,0 +1,229 @@
+package uconfig
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/omeid/uconfig/flat"
+	"github.com/omeid/uconfig/plugins"
+)
+
+// Command describes a command of the program, like db in
+// `app db migrate up`, and the part of the config that only applies to
+// it. The flags of a command are named within the command and only
+// accepted after its name, its fields are only required and validated
+// when the command is selected.
+type Command[C any] struct {
+	Name  string
+	Usage string
+
+	// Config returns the part of c that only applies to the command,
+	// it must be a pointer to a struct field of c, nested or embedded,
+	// e.g. func(c *Config) any { return &c.DB }.
+	// It is optional for commands that have no config of their own.
+	Config func(c *C) any
+
+	// Commands are the subcommands.
+	Commands []Command[C]
+}
+
+// Commands returns a plugin that sets up the command tree with the
+// plugins that select commands, like flags. The selected command is
+// available from Config.Command after Parse.
+func Commands[C any](commands ...Command[C]) plugins.Plugin {
+	tree, err := resolveTree(commands)
+
+	ext := extend(func(c plugins.Commander) { c.SetCommands(tree) })
+	ext.err = err
+
+	return &commandSet[C]{extension: ext, tree: tree}
+}
+
+// commandSet keeps the command tree for the config.
+type commandSet[C any] struct {
+	*extension[plugins.Commander]
+	tree []plugins.Command
+}
+
+// resolveTree resolves the commands to the fields of the
+// config, which are the same for every parse.
+func resolveTree[C any](commands []Command[C]) ([]plugins.Command, error) {
+	conf := new(C)
+	fields, err := flat.View(conf)
+	if err != nil {
+		return nil, err
+	}
+
+	root := reflect.ValueOf(conf).Elem()
+	addrs := fieldAddrs(root, nil)
+
+	return resolveCommands(conf, root, fields, addrs, commands, nil)
+}
+
+func resolveCommands[C any](conf *C, root reflect.Value, fields flat.Fields, addrs []uintptr, commands []Command[C], path []string) ([]plugins.Command, error) {
+	resolved := make([]plugins.Command, 0, len(commands))
+
+	for _, cmd := range commands {
+		path := append(path[:len(path):len(path)], cmd.Name)
+		if cmd.Name == "" || strings.HasPrefix(cmd.Name, "-") {
+			return nil, fmt.Errorf("uconfig: bad command name %q in %q", cmd.Name, strings.Join(path, " "))
+		}
+
+		rc := plugins.Command{Name: cmd.Name, Usage: cmd.Usage}
+
+		if cmd.Config != nil {
+			target := reflect.ValueOf(cmd.Config(conf))
+			if target.Kind() != reflect.Pointer || target.IsNil() || target.Elem().Kind() != reflect.Struct {
+				return nil, fmt.Errorf("uconfig: command %q: Config must return a pointer to a struct field of the config", strings.Join(path, " "))
+			}
+
+			base, t := target.Pointer(), target.Elem().Type()
+
+			prefix, ok := structPrefix(root, "", base, t)
+			if !ok {
+				return nil, fmt.Errorf("uconfig: command %q: Config must return a pointer to a struct field of the config", strings.Join(path, " "))
+			}
+
+			rc.Prefix = prefix
+			for i, f := range fields {
+				if addrs[i] >= base && addrs[i] < base+t.Size() {
+					name, _ := f.Name("")
+					rc.Fields = append(rc.Fields, name)
+				}
+			}
+		}
+
+		var err error
+		rc.Commands, err = resolveCommands(conf, root, fields, addrs, cmd.Commands, path)
+		if err != nil {
+			return nil, err
+		}
+
+		// the fields of a subcommand belong to the subcommand.
+		sub := commandScopes(rc.Commands, nil)
+		own := rc.Fields[:0]
+		for _, name := range rc.Fields {
+			if _, ok := sub[name]; !ok {
+				own = append(own, name)
+			}
+		}
+		rc.Fields = own
+
+		resolved = append(resolved, rc)
+	}
+
+	return resolved, nil
+}
+
+// fieldAddrs returns the address of the fields of rv in
+// the same order as flat.View.
+func fieldAddrs(rv reflect.Value, addrs []uintptr) []uintptr {
+	for i := 0; i < rv.NumField(); i++ {
+		fv := rv.Field(i)
+		if fv.Kind() == reflect.Struct {
+			addrs = fieldAddrs(fv, addrs)
+			continue
+		}
+		addrs = append(addrs, fv.UnsafeAddr())
+	}
+
+	return addrs
+}
+
+// structPrefix returns the flat prefix of the struct field of type t at
+// addr, which is the names of the non-embedded structs leading to it.
+func structPrefix(rv reflect.Value, prefix string, addr uintptr, t reflect.Type) (string, bool) {
+	ts := rv.Type()
+
+	for i := 0; i < rv.NumField(); i++ {
+		fv := rv.Field(i)
+		ft := ts.Field(i)
+
+		if fv.Kind() != reflect.Struct {
+			continue
+		}
+
+		fieldPrefix := prefix
+		if !ft.Anonymous {
+			if fieldPrefix == "" {
+				fieldPrefix = ft.Name
+			} else {
+				fieldPrefix = fieldPrefix + "." + ft.Name
+			}
+		}
+
+		if fv.UnsafeAddr() == addr && fv.Type() == t {
+			return fieldPrefix, true
+		}
+
+		if found, ok := structPrefix(fv, fieldPrefix, addr, t); ok {
+			return found, true
+		}
+	}
+
+	return "", false
+}
+
+// commandScopes maps the flat names of the fields that only apply to
+// a command to the names of the command and its parents joined by space.
+func commandScopes(commands []plugins.Command, path []string) map[string]string {
+	scopes := map[string]string{}
+
+	for _, cmd := range commands {
+		path := append(path[:len(path):len(path)], cmd.Name)
+		for _, name := range cmd.Fields {
+			scopes[name] = strings.Join(path, " ")
+		}
+
+		for name, scope := range commandScopes(cmd.Commands, path) {
+			scopes[name] = scope
+		}
+	}
+
+	return scopes
+}
+
+// commandTree returns the command tree of the config, if any.
+func (c *config[C]) commandTree() []plugins.Command {
+	for _, p := range c.plugins {
+		if s, ok := p.(*commandSet[C]); ok {
+			return s.tree
+		}
+	}
+	return nil
+}
+
+// selectedCommand returns the command selected by the plugins.
+func selectedCommand(ps []plugins.Plugin) []string {
+	for _, p := range ps {
+		if commander, ok := p.(plugins.Commander); ok {
+			if selected := commander.Command(); len(selected) > 0 {
+				return selected
+			}
+		}
+	}
+	return nil
+}
+
+// inactiveFields returns the fields of the commands that were not
+// selected, those are neither required nor validated.
+func inactiveFields(tree []plugins.Command, selected []string) map[string]bool {
+	inactive := map[string]bool{}
+	scope := strings.Join(selected, " ")
+
+	for name, fieldScope := range commandScopes(tree, nil) {
+		if fieldScope != scope && !strings.HasPrefix(scope, fieldScope+" ") {
+			inactive[name] = true
+		}
+	}
+
+	return inactive
+}
+
+func (c *config[C]) Command() []string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.command
+}
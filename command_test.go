@@ -0,0 +1,186 @@
+package uconfig_test
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/omeid/uconfig"
+	"github.com/omeid/uconfig/plugins/defaults"
+	"github.com/omeid/uconfig/plugins/flag"
+)
+
+type Serve struct {
+	Listen string `default:":8080" usage:"the address to listen on"`
+}
+
+type cmdConfig struct {
+	Command []string `flag:",command"`
+	Verbose bool
+
+	DB struct {
+		URL string `required:""`
+
+		Migrate struct {
+			DryRun bool
+
+			Up struct {
+				Steps int `validate:"min=1"`
+			}
+		}
+	}
+
+	Serve
+}
+
+var cmdTree = uconfig.Commands(
+	uconfig.Command[cmdConfig]{
+		Name:   "db",
+		Usage:  "database operations",
+		Config: func(c *cmdConfig) any { return &c.DB },
+		Commands: []uconfig.Command[cmdConfig]{
+			{
+				Name:   "migrate",
+				Config: func(c *cmdConfig) any { return &c.DB.Migrate },
+				Commands: []uconfig.Command[cmdConfig]{
+					{Name: "up", Usage: "apply migrations", Config: func(c *cmdConfig) any { return &c.DB.Migrate.Up }},
+					{Name: "status"},
+				},
+			},
+		},
+	},
+	uconfig.Command[cmdConfig]{
+		Name:   "serve",
+		Usage:  "run the server",
+		Config: func(c *cmdConfig) any { return &c.Serve },
+	},
+)
+
+func parseCommand(args ...string) (uconfig.Config[cmdConfig], *cmdConfig, error) {
+	conf := uconfig.New[cmdConfig](
+		defaults.New(),
+		cmdTree,
+		flag.New("testing", flag.ContinueOnError, args),
+	)
+
+	value, err := conf.Parse()
+	return conf, value, err
+}
+
+func TestCommands(t *testing.T) {
+	conf, value, err := parseCommand("-verbose", "db", "-url=postgres://db", "migrate", "-dryrun", "up", "-steps=3", "-verbose=false")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expect := &cmdConfig{Command: []string{"db", "migrate", "up"}, Serve: Serve{Listen: ":8080"}}
+	expect.DB.URL = "postgres://db"
+	expect.DB.Migrate.DryRun = true
+	expect.DB.Migrate.Up.Steps = 3
+
+	if diff := cmp.Diff(expect, value); diff != "" {
+		t.Error(diff)
+	}
+
+	if diff := cmp.Diff([]string{"db", "migrate", "up"}, conf.Command()); diff != "" {
+		t.Error(diff)
+	}
+}
+
+func TestCommandsScoped(t *testing.T) {
+	// fields of other commands are neither required nor validated.
+	conf, value, err := parseCommand("serve", "-listen=:9090")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if value.Listen != ":9090" {
+		t.Errorf("expected listen to be set, got %q", value.Listen)
+	}
+
+	if diff := cmp.Diff([]string{"serve"}, conf.Command()); diff != "" {
+		t.Error(diff)
+	}
+
+	conf, _, err = parseCommand()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if conf.Command() != nil {
+		t.Errorf("expected no command, got %v", conf.Command())
+	}
+}
+
+func TestCommandsErrors(t *testing.T) {
+	tests := []struct {
+		args   []string
+		expect string
+	}{
+		{
+			args:   []string{"-url=postgres://db", "db"},
+			expect: "flag provided but not defined: -url",
+		},
+		{
+			args:   []string{"dance"},
+			expect: `unknown command "dance", expecting one of: db, serve`,
+		},
+		{
+			args:   []string{"serve", "now"},
+			expect: "extra arguments provided: (now)",
+		},
+		{
+			args:   []string{"db", "migrate", "up"},
+			expect: "DB.URL (flag -url): missing required field\nDB.Migrate.Up.Steps (-steps): must be at least 1, got 0",
+		},
+	}
+
+	for _, tt := range tests {
+		_, _, err := parseCommand(tt.args...)
+		if err == nil || !strings.Contains(err.Error(), tt.expect) {
+			t.Errorf("%v: expected %q, got %v", tt.args, tt.expect, err)
+		}
+	}
+}
+
+func TestCommandsBadConfig(t *testing.T) {
+	outside := &struct{ A string }{}
+
+	conf := uconfig.New[cmdConfig](uconfig.Commands(uconfig.Command[cmdConfig]{
+		Name:   "bad",
+		Config: func(*cmdConfig) any { return outside },
+	}))
+
+	_, err := conf.Parse()
+	if err == nil {
+		t.Fatal("expected error for a command config outside of the config")
+	}
+}
+
+func TestCommandsUsage(t *testing.T) {
+	var stdout bytes.Buffer
+	uconfig.UsageOutput = &stdout
+
+	conf, _, err := parseCommand("db", "-h")
+	if !errors.Is(err, uconfig.ErrUsage) {
+		t.Fatalf("expected ErrUsage, got %v", err)
+	}
+
+	conf.Usage()
+
+	for _, expect := range []string{
+		"\nCommands:\n",
+		"    db            database operations\n",
+		"      migrate     \n",
+		"        up        apply migrations\n",
+		"\nCommand db:\nDB.URL    -url",
+		"\nCommand db migrate up:\nDB.Migrate.Up.Steps    -steps",
+		"\nCommand serve:\nListen    -listen",
+	} {
+		if !strings.Contains(stdout.String(), expect) {
+			t.Errorf("expected %q in:\n%s", expect, stdout.String())
+		}
+	}
+}
@@ -0,0 +1,51 @@
+package uconfig
+
+import (
+	"reflect"
+
+	"github.com/omeid/uconfig/flat"
+)
+
+// Change describes a field that has a different value between two
+// snapshots of the config. The values are formatted the same way the
+// plugins would provide them, with secret values masked.
+type Change struct {
+	Field string
+	Old   string
+	New   string
+}
+
+// diffFields returns the fields that have changed between the
+// two views of the config, in the order of the new fields.
+func diffFields(old flat.Fields, new flat.Fields) []Change {
+	previous := make(map[string]flat.Field, len(old))
+	for _, f := range old {
+		name, _ := f.Name("")
+		previous[name] = f
+	}
+
+	var changes []Change
+
+	for _, f := range new {
+		name, _ := f.Name("")
+
+		value := f.Interface()
+
+		var oldValue any
+		if prev, ok := previous[name]; ok {
+			oldValue = prev.Interface()
+		}
+
+		if reflect.DeepEqual(oldValue, value) {
+			continue
+		}
+
+		changes = append(changes, Change{
+			Field: name,
+			Old:   displayValue(f, formatValue(oldValue)),
+			New:   displayValue(f, formatValue(value)),
+		})
+	}
+
+	return changes
+}
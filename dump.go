@@ -0,0 +1,482 @@
+package uconfig
+
+import (
+	"bufio"
+	"bytes"
+	"encoding"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/omeid/uconfig/flat"
+	"github.com/omeid/uconfig/plugins"
+	"github.com/omeid/uconfig/plugins/env"
+)
+
+const sensitiveTag = "sensitive"
+
+func init() {
+	plugins.RegisterTag(sensitiveTag)
+}
+
+// Format is the output format of Dump.
+type Format string
+
+// The formats supported by Dump.
+const (
+	// FormatYAML nests the fields by their flat names.
+	FormatYAML Format = "yaml"
+	// FormatJSON nests the fields by their flat names.
+	FormatJSON Format = "json"
+	// FormatTOML nests the fields by their flat names in tables.
+	FormatTOML Format = "toml"
+	// FormatEnv lists the fields by their env names, e.g. REDIS_PORT=6379.
+	FormatEnv Format = "env"
+	// FormatFlat lists the fields by their flat names, e.g. Redis.Port=6379.
+	FormatFlat Format = "flat"
+)
+
+// fileTag returns the struct tag that the unmarshalers of the
+// file format name the fields with.
+func fileTag(format Format) (string, bool) {
+	switch format {
+	case FormatYAML, FormatJSON, FormatTOML:
+		return string(format), true
+	}
+	return "", false
+}
+
+// ErrPrintConfig is returned when the user requests the effective config
+// to be printed (e.g. -print-config flag), errors.As against a
+// *plugins.PrintConfig gives the requested format.
+var ErrPrintConfig = plugins.ErrPrintConfig
+
+// PrintConfig returns a plugin that enables the print config request of
+// the plugins that support it, like the -print-config flag, which makes
+// Run print the effective config with secrets masked, see Dump.
+func PrintConfig() plugins.Plugin {
+	return extend(plugins.ConfigPrinter.EnablePrintConfig)
+}
+
+// errNotParsed is returned by Dump before a successful Parse.
+var errNotParsed = errors.New("uconfig: config has not been parsed")
+
+func (c *config[C]) Dump(w io.Writer, format Format) error {
+	fields := c.current()
+	if fields == nil {
+		return errNotParsed
+	}
+
+	return dump(w, fields, format, dumpOptions{redact: maskValue})
+}
+
+// dumpOptions tunes the output of dump.
+type dumpOptions struct {
+	// redact returns the value to write instead of the
+	// value of the field, if it must not be shown.
+	redact func(f flat.Field) (string, bool)
+
+	// comments returns the lines to write before the field,
+	// formats without comments ignore them.
+	comments func(f flat.Field) []string
+
+	// keys is the struct tag to name and nest the fields by, as
+	// file unmarshalers do, instead of their flat names.
+	keys string
+}
+
+func (o dumpOptions) commentsOf(f flat.Field) []string {
+	if o.comments == nil {
+		return nil
+	}
+	return o.comments(f)
+}
+
+// maskValue masks the value of secret and sensitive fields.
+func maskValue(f flat.Field) (string, bool) {
+	return masked, isSensitive(f) && formatValue(f.Interface()) != ""
+}
+
+func dump(w io.Writer, fields flat.Fields, format Format, opts dumpOptions) error {
+	bw := bufio.NewWriter(w)
+
+	switch format {
+	case FormatYAML:
+		writeYAML(bw, dumpTree(fields, opts), 0)
+	case FormatJSON:
+		writeJSON(bw, dumpTree(fields, opts), 0)
+		_, _ = bw.WriteString("\n")
+	case FormatTOML:
+		writeTOML(bw, dumpTree(fields, opts), nil)
+	case FormatEnv:
+		writeList(bw, fields, env.Name, opts)
+	case FormatFlat:
+		writeList(bw, fields, func(f flat.Field) string {
+			name, _ := f.Name("")
+			return name
+		}, opts)
+	default:
+		return fmt.Errorf("uconfig: unknown format %q", format)
+	}
+
+	return bw.Flush()
+}
+
+// isSensitive reports whether the value of the field must be masked.
+func isSensitive(f flat.Field) bool {
+	_, secret := f.Tag(secretTag)
+	_, sensitive := f.Tag(sensitiveTag)
+	return secret || sensitive
+}
+
+func writeList(w *bufio.Writer, fields flat.Fields, nameOf func(flat.Field) string, opts dumpOptions) {
+	for _, f := range fields {
+		name := nameOf(f)
+		if name == "-" {
+			continue
+		}
+
+		value, redacted := opts.redact(f)
+		if !redacted {
+			value = formatValue(f.Interface())
+		}
+
+		writeComments(w, "", opts.commentsOf(f))
+		_, _ = fmt.Fprintf(w, "%s=%s\n", name, quoteValue(value))
+	}
+}
+
+func writeComments(w *bufio.Writer, indent string, comments []string) {
+	for _, comment := range comments {
+		_, _ = fmt.Fprintf(w, "%s# %s\n", indent, comment)
+	}
+}
+
+// quoteValue quotes values that a shell or dotenv parser
+// would otherwise split or interpret.
+func quoteValue(value string) string {
+	if strings.ContainsAny(value, " \t\r\n\"'`#$\\") {
+		return strconv.Quote(value)
+	}
+	return value
+}
+
+// dumpNode is a group of fields, or a single field when it has a value.
+type dumpNode struct {
+	key      string
+	value    any
+	leaf     bool
+	comments []string
+	children []*dumpNode
+}
+
+func (n *dumpNode) child(key string) *dumpNode {
+	for _, c := range n.children {
+		if c.key == key {
+			return c
+		}
+	}
+
+	c := &dumpNode{key: key}
+	n.children = append(n.children, c)
+	return c
+}
+
+// dumpTree nests the fields by the parts of their flat names, or
+// their keys, keeping the order of the fields.
+func dumpTree(fields flat.Fields, opts dumpOptions) *dumpNode {
+	root := &dumpNode{}
+
+	for _, f := range fields {
+		name, _ := f.Name("")
+		path := strings.Split(name, ".")
+
+		if opts.keys != "" {
+			path = f.Path(opts.keys)
+			if path == nil {
+				// the unmarshalers skip the field.
+				continue
+			}
+		}
+
+		node := root
+		for _, part := range path {
+			node = node.child(part)
+		}
+
+		node.comments = opts.commentsOf(f)
+
+		if value, redacted := opts.redact(f); redacted {
+			node.value, node.leaf = value, true
+			continue
+		}
+
+		rv := reflect.ValueOf(f.Interface())
+		if rv.Kind() == reflect.Map && !isText(rv) {
+			node.children = mapNodes(rv)
+			continue
+		}
+
+		node.value, node.leaf = dumpValue(rv), true
+	}
+
+	return root
+}
+
+func mapNodes(rv reflect.Value) []*dumpNode {
+	nodes := make([]*dumpNode, 0, rv.Len())
+
+	iter := rv.MapRange()
+	for iter.Next() {
+		nodes = append(nodes, &dumpNode{
+			key:   formatValue(iter.Key().Interface()),
+			value: dumpValue(iter.Value()),
+			leaf:  true,
+		})
+	}
+
+	sort.Slice(nodes, func(i, j int) bool {
+		return nodes[i].key < nodes[j].key
+	})
+
+	return nodes
+}
+
+var textMarshalerType = reflect.TypeOf(new(encoding.TextMarshaler)).Elem()
+var stringerType = reflect.TypeOf(new(fmt.Stringer)).Elem()
+
+// isText reports whether the value has its own text form, e.g. time.Duration.
+func isText(rv reflect.Value) bool {
+	return rv.Type().Implements(textMarshalerType) || rv.Type().Implements(stringerType)
+}
+
+// dumpValue converts the value to nil, bool, int64, uint64, float64,
+// string, or a []any of those.
+func dumpValue(rv reflect.Value) any {
+	for rv.Kind() == reflect.Pointer || rv.Kind() == reflect.Interface {
+		if rv.IsNil() {
+			return nil
+		}
+		rv = rv.Elem()
+	}
+
+	if !rv.IsValid() {
+		return nil
+	}
+
+	if isText(rv) {
+		return formatValue(rv.Interface())
+	}
+
+	switch rv.Kind() {
+	case reflect.Bool:
+		return rv.Bool()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return rv.Int()
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return rv.Uint()
+	case reflect.Float32, reflect.Float64:
+		return rv.Float()
+	case reflect.Slice, reflect.Array:
+		values := make([]any, rv.Len())
+		for i := range values {
+			values[i] = dumpValue(rv.Index(i))
+		}
+		return values
+	}
+
+	return formatValue(rv.Interface())
+}
+
+// jsonMarshal is json.Marshal without escaping HTML characters,
+// as the output is never embedded in HTML.
+func jsonMarshal(v any) ([]byte, error) {
+	var buf bytes.Buffer
+
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+
+	err := enc.Encode(v)
+	if err != nil {
+		return nil, err
+	}
+
+	return bytes.TrimSuffix(buf.Bytes(), []byte("\n")), nil
+}
+
+func writeJSON(w *bufio.Writer, node *dumpNode, depth int) {
+	if node.leaf {
+		value, err := jsonMarshal(node.value)
+		if err != nil {
+			// e.g. NaN, which JSON has no number for.
+			value, _ = jsonMarshal(fmt.Sprint(node.value))
+		}
+		_, _ = w.Write(value)
+		return
+	}
+
+	if len(node.children) == 0 {
+		_, _ = w.WriteString("{}")
+		return
+	}
+
+	indent := strings.Repeat("  ", depth+1)
+
+	_, _ = w.WriteString("{\n")
+	for i, c := range node.children {
+		key, _ := jsonMarshal(c.key)
+		_, _ = fmt.Fprintf(w, "%s%s: ", indent, key)
+		writeJSON(w, c, depth+1)
+		if i < len(node.children)-1 {
+			_, _ = w.WriteString(",")
+		}
+		_, _ = w.WriteString("\n")
+	}
+	_, _ = w.WriteString(indent[2:] + "}")
+}
+
+func writeYAML(w *bufio.Writer, node *dumpNode, depth int) {
+	indent := strings.Repeat("  ", depth)
+
+	for _, c := range node.children {
+		key := yamlString(c.key)
+
+		writeComments(w, indent, c.comments)
+
+		switch {
+		case c.leaf:
+			_, _ = fmt.Fprintf(w, "%s%s: %s\n", indent, key, yamlValue(c.value))
+		case len(c.children) == 0:
+			_, _ = fmt.Fprintf(w, "%s%s: {}\n", indent, key)
+		default:
+			_, _ = fmt.Fprintf(w, "%s%s:\n", indent, key)
+			writeYAML(w, c, depth+1)
+		}
+	}
+}
+
+// writeTOML writes the leaves of the node as keys of the table at
+// path, followed by the groups as tables of their own.
+func writeTOML(w *bufio.Writer, node *dumpNode, path []string) {
+	var groups []*dumpNode
+
+	for _, c := range node.children {
+		if !c.leaf {
+			groups = append(groups, c)
+			continue
+		}
+
+		writeComments(w, "", c.comments)
+
+		// TOML has no null, leave it out.
+		if c.value == nil {
+			_, _ = fmt.Fprintf(w, "# %s =\n", tomlKey(c.key))
+			continue
+		}
+
+		_, _ = fmt.Fprintf(w, "%s = %s\n", tomlKey(c.key), tomlValue(c.value))
+	}
+
+	for _, c := range groups {
+		path := append(path[:len(path):len(path)], tomlKey(c.key))
+
+		_, _ = w.WriteString("\n")
+		writeComments(w, "", c.comments)
+		_, _ = fmt.Fprintf(w, "[%s]\n", strings.Join(path, "."))
+		writeTOML(w, c, path)
+	}
+}
+
+func tomlKey(key string) string {
+	for _, r := range key {
+		if !(r == '_' || r == '-' || r >= '0' && r <= '9' || r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z') {
+			return tomlValue(key)
+		}
+	}
+
+	if key == "" {
+		return `""`
+	}
+
+	return key
+}
+
+func tomlValue(value any) string {
+	switch value := value.(type) {
+	case string:
+		// JSON strings are valid TOML basic strings.
+		s, _ := jsonMarshal(value)
+		return string(s)
+	case []any:
+		values := make([]string, 0, len(value))
+		for _, v := range value {
+			if v != nil {
+				values = append(values, tomlValue(v))
+			}
+		}
+		return "[" + strings.Join(values, ", ") + "]"
+	case float64:
+		switch {
+		case math.IsNaN(value):
+			return "nan"
+		case math.IsInf(value, 1):
+			return "inf"
+		case math.IsInf(value, -1):
+			return "-inf"
+		}
+
+		s := strconv.FormatFloat(value, 'g', -1, 64)
+		if !strings.ContainsAny(s, ".eEn") {
+			s += ".0"
+		}
+		return s
+	}
+
+	return fmt.Sprint(value)
+}
+
+func yamlValue(value any) string {
+	switch value := value.(type) {
+	case nil:
+		return "null"
+	case string:
+		return yamlString(value)
+	case []any:
+		values := make([]string, len(value))
+		for i, v := range value {
+			values[i] = yamlValue(v)
+		}
+		return "[" + strings.Join(values, ", ") + "]"
+	case float64:
+		return strconv.FormatFloat(value, 'g', -1, 64)
+	}
+
+	return fmt.Sprint(value)
+}
+
+// yamlString quotes the string when it would otherwise be read
+// as something else, like a number, a bool, or a nested structure.
+func yamlString(s string) string {
+	if s == "" || s != strings.TrimSpace(s) ||
+		strings.ContainsAny(s, ":#{}[],&*!|>'\"%@`\\\n\t") ||
+		strings.ContainsAny(s[:1], "-?~") {
+		return strconv.Quote(s)
+	}
+
+	switch strings.ToLower(s) {
+	case "true", "false", "yes", "no", "on", "off", "y", "n", "null":
+		return strconv.Quote(s)
+	}
+
+	if _, err := strconv.ParseFloat(s, 64); err == nil {
+		return strconv.Quote(s)
+	}
+
+	return s
+}
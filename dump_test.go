@@ -0,0 +1,200 @@
+package uconfig_test
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/omeid/uconfig"
+	"github.com/omeid/uconfig/plugins"
+	"github.com/omeid/uconfig/plugins/defaults"
+	"github.com/omeid/uconfig/plugins/env"
+	"github.com/omeid/uconfig/plugins/flag"
+)
+
+type dumpConfig struct {
+	Name    string         `default:"api server"`
+	Port    int            `default:"8080" env:"LISTEN_PORT"`
+	Timeout time.Duration  `default:"5s"`
+	Tags    []string       `default:"a,b"`
+	Labels  map[string]int `default:"b:2,a:1"`
+
+	Database struct {
+		URL      string `default:"postgres://db" sensitive:""`
+		Password string `default:"hunter2" secret:""`
+		Token    string `secret:""`
+	}
+}
+
+func parseDump(t *testing.T) uconfig.Config[dumpConfig] {
+	t.Helper()
+
+	conf := uconfig.New[dumpConfig](defaults.New(), env.New())
+	_, err := conf.Parse()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return conf
+}
+
+func TestDump(t *testing.T) {
+	tests := []struct {
+		format uconfig.Format
+		expect string
+	}{
+		{
+			format: uconfig.FormatYAML,
+			expect: `Name: api server
+Port: 8080
+Timeout: 5s
+Tags: [a, b]
+Labels:
+  a: 1
+  b: 2
+Database:
+  URL: "******"
+  Password: "******"
+  Token: ""
+`,
+		},
+		{
+			format: uconfig.FormatJSON,
+			expect: `{
+  "Name": "api server",
+  "Port": 8080,
+  "Timeout": "5s",
+  "Tags": ["a","b"],
+  "Labels": {
+    "a": 1,
+    "b": 2
+  },
+  "Database": {
+    "URL": "******",
+    "Password": "******",
+    "Token": ""
+  }
+}
+`,
+		},
+		{
+			format: uconfig.FormatTOML,
+			expect: `Name = "api server"
+Port = 8080
+Timeout = "5s"
+Tags = ["a", "b"]
+
+[Labels]
+a = 1
+b = 2
+
+[Database]
+URL = "******"
+Password = "******"
+Token = ""
+`,
+		},
+		{
+			format: uconfig.FormatEnv,
+			expect: `NAME="api server"
+LISTEN_PORT=8080
+TIMEOUT=5s
+TAGS=a,b
+LABELS=a:1,b:2
+DATABASE_URL=******
+DATABASE_PASSWORD=******
+DATABASE_TOKEN=
+`,
+		},
+		{
+			format: uconfig.FormatFlat,
+			expect: `Name="api server"
+Port=8080
+Timeout=5s
+Tags=a,b
+Labels=a:1,b:2
+Database.URL=******
+Database.Password=******
+Database.Token=
+`,
+		},
+	}
+
+	conf := parseDump(t)
+
+	for _, tt := range tests {
+		t.Run(string(tt.format), func(t *testing.T) {
+			var buf bytes.Buffer
+			err := conf.Dump(&buf, tt.format)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if diff := cmp.Diff(tt.expect, buf.String()); diff != "" {
+				t.Error(diff)
+			}
+		})
+	}
+}
+
+func TestDumpErrors(t *testing.T) {
+	conf := uconfig.New[dumpConfig](defaults.New())
+
+	err := conf.Dump(&bytes.Buffer{}, uconfig.FormatYAML)
+	if err == nil {
+		t.Fatal("expected error before parse")
+	}
+
+	_, err = conf.Parse()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = conf.Dump(&bytes.Buffer{}, "ini")
+	if err == nil || err.Error() != `uconfig: unknown format "ini"` {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestPrintConfigFlag(t *testing.T) {
+	tests := []struct {
+		args   []string
+		format string
+	}{
+		{args: []string{"-print-config"}, format: "yaml"},
+		{args: []string{"-port=9090", "-print-config=json"}, format: "json"},
+	}
+
+	for _, tt := range tests {
+		conf := uconfig.New[dumpConfig](
+			defaults.New(),
+			uconfig.PrintConfig(),
+			flag.New("testing", flag.ContinueOnError, tt.args),
+		)
+
+		_, err := conf.Parse()
+		if !errors.Is(err, uconfig.ErrPrintConfig) {
+			t.Fatalf("%v: expected ErrPrintConfig, got %v", tt.args, err)
+		}
+
+		var print *plugins.PrintConfig
+		if !errors.As(err, &print) {
+			t.Fatalf("%v: expected *plugins.PrintConfig, got %v", tt.args, err)
+		}
+
+		if print.Format != tt.format {
+			t.Errorf("%v: expected format %q, got %q", tt.args, tt.format, print.Format)
+		}
+	}
+
+	// the flag is only there once enabled.
+	conf := uconfig.New[dumpConfig](flag.New("testing", flag.ContinueOnError, []string{"-print-config"}))
+
+	_, err := conf.Parse()
+	expect := "flag provided but not defined: -print-config"
+	if err == nil || err.Error() != expect {
+		t.Errorf("expected (%s) but got (%v)", expect, err)
+	}
+}
@@ -0,0 +1,89 @@
+package uconfig
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/omeid/uconfig/flat"
+)
+
+// FieldError describes a failure to set or accept the value of a
+// field, it carries the field name, the plugin and key the value came
+// from, the raw value, and the underlying cause.
+type FieldError = flat.FieldError
+
+// ErrRequired is the cause of the FieldError for required fields that
+// were not set by any plugin.
+var ErrRequired = errors.New("missing required field")
+
+// ParseError aggregates all the failures of a single Parse, it
+// supports errors.Is and errors.As against any of them.
+type ParseError struct {
+	Errors []error
+}
+
+func (e *ParseError) Error() string {
+	msgs := make([]string, len(e.Errors))
+	for i, err := range e.Errors {
+		msgs[i] = err.Error()
+	}
+	return strings.Join(msgs, "\n")
+}
+
+func (e *ParseError) Unwrap() []error {
+	return e.Errors
+}
+
+// FieldErrors returns the errors that are attributed to a field
+// or source, in the order they happened.
+func (e *ParseError) FieldErrors() []*FieldError {
+	var fes []*FieldError
+	for _, err := range e.Errors {
+		var fe *FieldError
+		if errors.As(err, &fe) {
+			fes = append(fes, fe)
+		}
+	}
+	return fes
+}
+
+// add appends the errors, unpacking any joined errors so that
+// each failure is listed on its own.
+func (e *ParseError) add(errs ...error) {
+	for _, err := range errs {
+		if err == nil {
+			continue
+		}
+
+		// a joined error of several fields is unpacked, but not a
+		// field error that happens to wrap several causes.
+		if _, ok := err.(*FieldError); !ok {
+			if joined, ok := err.(interface{ Unwrap() []error }); ok {
+				e.add(joined.Unwrap()...)
+				continue
+			}
+		}
+
+		e.Errors = append(e.Errors, err)
+	}
+}
+
+// failed returns the names of the fields that have failed so far.
+func (e *ParseError) failed() map[string]bool {
+	names := map[string]bool{}
+	for _, fe := range e.FieldErrors() {
+		if fe.Field != "" {
+			names[fe.Field] = true
+		}
+	}
+	return names
+}
+
+// err returns nil when there are no errors, so that
+// a nil *ParseError is never returned as an error.
+func (e *ParseError) err() error {
+	if len(e.Errors) == 0 {
+		return nil
+	}
+	return e
+}
@@ -0,0 +1,114 @@
+package uconfig_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"github.com/omeid/uconfig"
+	"github.com/omeid/uconfig/plugins/defaults"
+	"github.com/omeid/uconfig/plugins/env"
+	"github.com/omeid/uconfig/plugins/flag"
+)
+
+type fErrors struct {
+	Port    int           `default:"eighty"`
+	Timeout time.Duration `env:"ERRORS_TIMEOUT"`
+	Debug   bool
+	Name    string `required:""`
+	Level   string `default:"trace" validate:"oneof=debug|info"`
+}
+
+func TestParseErrorAggregates(t *testing.T) {
+	t.Setenv("ERRORS_TIMEOUT", "soon")
+
+	args := []string{"-debug=maybe"}
+
+	conf := uconfig.New[fErrors](
+		defaults.New(),
+		env.New(),
+		flag.New("testing", flag.ContinueOnError, args),
+	)
+
+	_, err := conf.Parse()
+	if err == nil {
+		t.Fatal("expected error, got nil")
+	}
+
+	var parseErr *uconfig.ParseError
+	if !errors.As(err, &parseErr) {
+		t.Fatalf("expected *uconfig.ParseError, got %T", err)
+	}
+
+	expect := []*uconfig.FieldError{
+		{Field: "Port", Plugin: "default", Value: "eighty"},
+		{Field: "Timeout", Plugin: "env", Key: "ERRORS_TIMEOUT", Value: "soon"},
+		{Field: "Debug", Plugin: "flag", Key: "-debug", Value: "maybe"},
+		{Field: "Name", Plugin: "required", Key: "flag -name, env NAME", Err: uconfig.ErrRequired},
+		{Field: "Level", Plugin: "validate", Key: "-level, LEVEL", Value: "trace"},
+	}
+
+	ignoreCause := cmpopts.IgnoreFields(uconfig.FieldError{}, "Err")
+	if diff := cmp.Diff(expect, parseErr.FieldErrors(), ignoreCause); diff != "" {
+		t.Error(diff)
+	}
+
+	if !errors.Is(err, uconfig.ErrRequired) {
+		t.Error("expected errors.Is to find ErrRequired")
+	}
+
+	var fieldErr *uconfig.FieldError
+	if !errors.As(err, &fieldErr) || fieldErr.Field != "Port" {
+		t.Errorf("expected errors.As to find the Port error, got %v", fieldErr)
+	}
+
+	expectMsg := `Port (default): strconv.ParseInt: parsing "eighty": invalid syntax`
+	if fieldErr.Error() != expectMsg {
+		t.Errorf("expected (%s) but got (%s)", expectMsg, fieldErr)
+	}
+}
+
+func TestParseErrorUsage(t *testing.T) {
+	conf := uconfig.New[fErrors](
+		defaults.New(),
+		flag.New("testing", flag.ContinueOnError, []string{"-h"}),
+	)
+
+	_, err := conf.Parse()
+	if !errors.Is(err, uconfig.ErrUsage) {
+		t.Fatalf("expected ErrUsage, got %v", err)
+	}
+}
+
+func TestParseErrorJoined(t *testing.T) {
+	type Config struct {
+		X int
+		Y int
+		A string `required:""`
+		B string `required:""`
+		C string `validate:"nonzero"`
+		D string `validate:"nonzero"`
+	}
+
+	t.Setenv("X", "ten")
+	t.Setenv("Y", "twenty")
+
+	_, err := uconfig.New[Config](env.New()).Parse()
+
+	var parseErr *uconfig.ParseError
+	if !errors.As(err, &parseErr) {
+		t.Fatalf("expected *uconfig.ParseError, got %T", err)
+	}
+
+	var got []string
+	for _, fe := range parseErr.FieldErrors() {
+		got = append(got, fe.Field+" ("+fe.Plugin+")")
+	}
+
+	expect := []string{"X (env)", "Y (env)", "A (required)", "B (required)", "C (validate)", "D (validate)"}
+	if diff := cmp.Diff(expect, got); diff != "" {
+		t.Error(diff)
+	}
+}
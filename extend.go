@@ -0,0 +1,48 @@
+package uconfig
+
+import (
+	"github.com/omeid/uconfig/plugins"
+)
+
+// extend returns an Extension that calls set with each of the plugins
+// that implement T, which is how the plugins of this package, like
+// EnvPrefix and Version, set up the other plugins.
+func extend[T any](set func(T)) *extension[T] {
+	return &extension[T]{set: set}
+}
+
+var _ plugins.Extension = (*extension[any])(nil)
+
+type extension[T any] struct {
+	set func(T)
+	err error
+}
+
+func (e *extension[T]) Extend(ps []plugins.Plugin) error {
+	if e.err != nil {
+		return e.err
+	}
+
+	for _, p := range ps {
+		if t, ok := p.(T); ok {
+			e.set(t)
+		}
+	}
+
+	return nil
+}
+
+func (e *extension[T]) Parse() error {
+	return nil
+}
+
+// setsUp marks the extensions that set up other plugins, which are set
+// up before all the other plugins wherever they are registered, so that
+// the first parse is no different from the ones after it.
+func (*extension[T]) setsUp() {}
+
+// setsUp reports whether the plugin sets up other plugins.
+func setsUp(p plugins.Plugin) bool {
+	_, ok := p.(interface{ setsUp() })
+	return ok
+}
@@ -13,8 +13,10 @@ var _ Field = (*field)(nil)
 type field struct {
 	name   string
 	prefix string
+	path   []pathElem
 
-	meta map[string]string
+	meta    map[string]string
+	sources []Source
 
 	tag   reflect.StructTag
 	field reflect.Value
@@ -49,6 +51,10 @@ func (f *field) Name(tag string) (string, bool) {
 	return f.prefix + "." + name, explicit
 }
 
+func (f *field) Path(tag string) []string {
+	return keyPath(f.path, tag)
+}
+
 func (f *field) Meta() map[string]string {
 	return f.meta
 }
@@ -61,6 +67,10 @@ func (f *field) Tag(key string) (string, bool) {
 }
 
 func (f *field) Interface() any {
+	// unexported fields are never set by plugins.
+	if !f.field.CanInterface() {
+		return nil
+	}
 	return f.field.Interface()
 }
 
@@ -74,6 +84,31 @@ func (f *field) Ptr() any {
 	return f.field.Addr().Interface()
 }
 
+func (f *field) SetFrom(src Source) error {
+	err := f.Set(src.Value)
+	if err != nil {
+		name, _ := f.Name("")
+		return &FieldError{
+			Field:  name,
+			Plugin: src.Plugin,
+			Key:    src.Key,
+			Value:  src.Value,
+			Err:    err,
+		}
+	}
+
+	f.Record(src)
+	return nil
+}
+
+func (f *field) Record(src Source) {
+	f.sources = append(f.sources, src)
+}
+
+func (f *field) Sources() []Source {
+	return f.sources
+}
+
 var textUnmarshalerType = reflect.TypeOf(new(encoding.TextUnmarshaler)).Elem()
 
 func (f *field) Set(value string) error {
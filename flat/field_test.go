@@ -55,3 +55,38 @@ func TestField(t *testing.T) {
 		t.Errorf("expected String() to return value set via pointer but got %v", def)
 	}
 }
+
+func TestFieldSources(t *testing.T) {
+	type Config struct {
+		Port int
+	}
+
+	conf := &Config{}
+	fs, err := flat.View(conf)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	port := fs[0]
+
+	err = port.SetFrom(flat.Source{Plugin: "default", Value: "80"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = port.SetFrom(flat.Source{Plugin: "env", Key: "PORT", Value: "not-a-number"})
+	if err == nil {
+		t.Fatal("expected error for bad value, got nil")
+	}
+
+	port.Record(flat.Source{Plugin: "file", Key: "config.json", Value: "8080"})
+
+	expect := []flat.Source{
+		{Plugin: "default", Value: "80"},
+		{Plugin: "file", Key: "config.json", Value: "8080"},
+	}
+
+	if diff := cmp.Diff(expect, port.Sources()); diff != "" {
+		t.Error(diff)
+	}
+}
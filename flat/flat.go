@@ -24,17 +24,88 @@ type Field interface {
 
 	Tag(key string) (string, bool)
 
+	// Path returns the keys leading to this field as seen by decoders
+	// that name the fields with the given struct tag, e.g. json, yaml,
+	// or toml, see KeyPath. It is nil when the decoder skips the field.
+	Path(tag string) []string
+
 	Meta() map[string]string
 
 	Interface() any
 	Set(string) error
 
+	// SetFrom is like Set but also records the source in the
+	// field history when the value is set successfully, otherwise
+	// it returns a *FieldError.
+	SetFrom(Source) error
+
+	// Record adds the source to the field history without setting
+	// the value, it is used for values set by Walkers.
+	Record(Source)
+
+	// Sources returns the history of the field values in the order
+	// they were set, the last one is the effective value.
+	Sources() []Source
+
 	// returns the Ptr to this value.
 	// It is used by complex decoders like uconfig-cue.
 	Ptr() any
 }
 
-var caser = cases.Title(language.Und, cases.NoLower)
+// Source describes where a field value came from.
+type Source struct {
+	// Plugin is the kind of plugin that set the value, e.g. env.
+	Plugin string
+	// Key is the name the value was found under, e.g. REDIS_PORT,
+	// -redis-port or a file path.
+	Key string
+	// Value is the raw value as provided by the plugin.
+	Value string
+}
+
+// FieldError describes a failure to set or accept the value of a field.
+type FieldError struct {
+	// Field is the name of the field, it is empty for errors that
+	// can not be attributed to a single field, e.g. a broken file.
+	Field string
+	// Plugin is the kind of plugin that failed, e.g. env.
+	Plugin string
+	// Key is the name the value was found under, e.g. REDIS_PORT,
+	// -redis-port or a file path.
+	Key string
+	// Value is the raw value as provided by the plugin.
+	Value string
+	// Err is the underlying cause.
+	Err error
+}
+
+func (e *FieldError) Error() string {
+	source := e.Key
+	if source == "" {
+		source = e.Plugin
+	}
+
+	switch {
+	case e.Field == "" && source == "":
+		return e.Err.Error()
+	case e.Field == "":
+		return source + ": " + e.Err.Error()
+	case source == "":
+		return e.Field + ": " + e.Err.Error()
+	}
+
+	return e.Field + " (" + source + "): " + e.Err.Error()
+}
+
+func (e *FieldError) Unwrap() error {
+	return e.Err
+}
+
+// title capitalises s, a Caser is not safe for concurrent
+// use and the configs can be viewed concurrently, e.g. by Live.
+func title(s string) string {
+	return cases.Title(language.Und, cases.NoLower).String(s)
+}
 
 // View provides a flat view of the provided structs an array of fields.
 // sub-struct fields are prefixed with the struct key (not type) followed by a dot,
@@ -45,11 +116,11 @@ func View(s any) (Fields, error) {
 		return nil, err
 	}
 
-	return walkStruct("", rs)
+	return walkStruct("", nil, rs)
 }
 
-func walkStruct(prefix string, rs reflect.Value) ([]Field, error) {
-	prefix = caser.String(prefix)
+func walkStruct(prefix string, path []pathElem, rs reflect.Value) ([]Field, error) {
+	prefix = title(prefix)
 
 	fields := []Field{}
 
@@ -63,6 +134,7 @@ func walkStruct(prefix string, rs reflect.Value) ([]Field, error) {
 
 		case reflect.Struct:
 			structPrefix := prefix
+			structPath := appendPath(path, ft)
 			if !ft.Anonymous {
 				// Unless it is anonymous struct, append the field name to the prefix.
 				if structPrefix == "" {
@@ -71,7 +143,7 @@ func walkStruct(prefix string, rs reflect.Value) ([]Field, error) {
 					structPrefix = structPrefix + "." + ft.Name
 				}
 			}
-			fs, err := walkStruct(structPrefix, fv)
+			fs, err := walkStruct(structPrefix, structPath, fv)
 			if err != nil {
 				return nil, err
 			}
@@ -88,6 +160,7 @@ func walkStruct(prefix string, rs reflect.Value) ([]Field, error) {
 			fields = append(fields, &field{
 				name:   fieldName,
 				prefix: prefix,
+				path:   appendPath(path, ft),
 				meta:   make(map[string]string, 5),
 				tag:    ft.Tag,
 				field:  fv,
@@ -98,6 +171,13 @@ func walkStruct(prefix string, rs reflect.Value) ([]Field, error) {
 	return fields, nil
 }
 
+// appendPath returns a new path so that siblings don't share
+// the backing array.
+func appendPath(path []pathElem, ft reflect.StructField) []pathElem {
+	elem := pathElem{name: ft.Name, tag: ft.Tag, anonymous: ft.Anonymous}
+	return append(path[:len(path):len(path)], elem)
+}
+
 func unwrap(s any) (reflect.Value, error) {
 	rs := reflect.ValueOf(s)
 
@@ -160,3 +160,79 @@ func TestFlattenTypes(t *testing.T) {
 		t.Error(diff)
 	}
 }
+
+func TestViewPath(t *testing.T) {
+	type Inner struct {
+		Port int `uconfig:".Address"`
+	}
+
+	type Embedded struct {
+		Version string
+	}
+
+	type Config struct {
+		Embedded
+		First  Inner
+		Second Inner
+	}
+
+	fs, err := flat.View(&Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expect := [][]string{
+		{"Version"},
+		{"First", "Port"},
+		{"Second", "Port"},
+	}
+
+	paths := make([][]string, len(fs))
+	for i, f := range fs {
+		paths[i] = f.Path("json")
+	}
+
+	if diff := cmp.Diff(expect, paths); diff != "" {
+		t.Error(diff)
+	}
+}
+
+func TestViewPathTags(t *testing.T) {
+	type Embedded struct {
+		Version string `json:"version"`
+	}
+
+	type Inner struct {
+		Port int `json:"port,omitempty" yaml:"port_number"`
+	}
+
+	type Config struct {
+		Embedded
+		Named   Embedded `json:"named" yaml:",inline"`
+		Server  Inner    `json:"server" yaml:"srv"`
+		Ignored string   `json:"-" yaml:"ignored"`
+		Level   string
+	}
+
+	fs, err := flat.View(&Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expect := map[string][][]string{
+		"json": {{"version"}, {"named", "version"}, {"server", "port"}, nil, {"Level"}},
+		"yaml": {{"embedded", "version"}, {"version"}, {"srv", "port_number"}, {"ignored"}, {"level"}},
+		"toml": {{"Version"}, {"Named", "Version"}, {"Server", "Port"}, {"Ignored"}, {"Level"}},
+	}
+
+	for tag, paths := range expect {
+		got := make([][]string, len(fs))
+		for i, f := range fs {
+			got[i] = f.Path(tag)
+		}
+
+		if diff := cmp.Diff(paths, got); diff != "" {
+			t.Errorf("%s: %s", tag, diff)
+		}
+	}
+}
@@ -0,0 +1,80 @@
+package flat
+
+import (
+	"strings"
+	"unicode"
+)
+
+// Naming turns the flat name of a field, e.g. Redis.MaxConns, into the
+// name a plugin knows it by, e.g. REDIS_MAX_CONNS. Plugins that take a
+// Naming use it for the fields without an explicit name.
+type Naming func(name string) string
+
+// Words splits the name into words at dots, underscores, dashes, spaces,
+// and case changes, keeping acronyms and trailing digits together,
+// e.g. HTTPServer.MaxConns2 is [HTTP Server Max Conns2].
+func Words(name string) []string {
+	var words []string
+
+	for _, part := range strings.FieldsFunc(name, func(r rune) bool {
+		return r == '.' || r == '_' || r == '-' || unicode.IsSpace(r)
+	}) {
+		runes := []rune(part)
+		start := 0
+
+		for i := 1; i < len(runes); i++ {
+			r, prev := runes[i], runes[i-1]
+			if !unicode.IsUpper(r) {
+				continue
+			}
+
+			// maxConns, db2Host, and HTTPServer.
+			if unicode.IsLower(prev) || unicode.IsDigit(prev) ||
+				(unicode.IsUpper(prev) && i+1 < len(runes) && unicode.IsLower(runes[i+1])) {
+				words = append(words, string(runes[start:i]))
+				start = i
+			}
+		}
+
+		words = append(words, string(runes[start:]))
+	}
+
+	return words
+}
+
+// AsIs keeps the name as it is, e.g. Redis.MaxConns.
+func AsIs(name string) string {
+	return name
+}
+
+// SnakeCase names Redis.MaxConns redis_max_conns.
+func SnakeCase(name string) string {
+	return strings.ToLower(strings.Join(Words(name), "_"))
+}
+
+// ScreamingSnakeCase names Redis.MaxConns REDIS_MAX_CONNS.
+func ScreamingSnakeCase(name string) string {
+	return strings.ToUpper(strings.Join(Words(name), "_"))
+}
+
+// KebabCase names Redis.MaxConns redis-max-conns.
+func KebabCase(name string) string {
+	return strings.ToLower(strings.Join(Words(name), "-"))
+}
+
+// CamelCase names Redis.MaxConns redisMaxConns.
+func CamelCase(name string) string {
+	words := Words(name)
+
+	for i, word := range words {
+		word = strings.ToLower(word)
+		if i > 0 {
+			runes := []rune(word)
+			runes[0] = unicode.ToUpper(runes[0])
+			word = string(runes)
+		}
+		words[i] = word
+	}
+
+	return strings.Join(words, "")
+}
@@ -0,0 +1,48 @@
+package flat_test
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/omeid/uconfig/flat"
+)
+
+func TestWords(t *testing.T) {
+	tests := map[string][]string{
+		"Port":                 {"Port"},
+		"MaxConns":             {"Max", "Conns"},
+		"Redis.MaxConns":       {"Redis", "Max", "Conns"},
+		"HTTPServer":           {"HTTP", "Server"},
+		"Server.HTTP":          {"Server", "HTTP"},
+		"DB2Host":              {"DB2", "Host"},
+		"ReadTimeout_ms":       {"Read", "Timeout", "ms"},
+		"already-kebab-case":   {"already", "kebab", "case"},
+		"userID":               {"user", "ID"},
+		"Service.Port.Numbers": {"Service", "Port", "Numbers"},
+	}
+
+	for name, expect := range tests {
+		if diff := cmp.Diff(expect, flat.Words(name)); diff != "" {
+			t.Errorf("%s: %s", name, diff)
+		}
+	}
+}
+
+func TestNaming(t *testing.T) {
+	tests := []struct {
+		naming flat.Naming
+		expect string
+	}{
+		{flat.AsIs, "Redis.HTTPServer.MaxConns"},
+		{flat.SnakeCase, "redis_http_server_max_conns"},
+		{flat.ScreamingSnakeCase, "REDIS_HTTP_SERVER_MAX_CONNS"},
+		{flat.KebabCase, "redis-http-server-max-conns"},
+		{flat.CamelCase, "redisHttpServerMaxConns"},
+	}
+
+	for _, tt := range tests {
+		if got := tt.naming("Redis.HTTPServer.MaxConns"); got != tt.expect {
+			t.Errorf("expected %q but got %q", tt.expect, got)
+		}
+	}
+}
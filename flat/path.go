@@ -0,0 +1,61 @@
+package flat
+
+import (
+	"reflect"
+	"strings"
+)
+
+// pathElem is a struct field leading to a field.
+type pathElem struct {
+	name      string
+	tag       reflect.StructTag
+	anonymous bool
+}
+
+// keyPath returns the keys of the path the way decoders that name
+// fields with tag see them, like encoding/json and gopkg.in/yaml:
+//
+//   - the name in the tag is the key, otherwise the field name, which
+//     is lowercased for yaml.
+//   - fields tagged with "-" are skipped, and so the path is nil.
+//   - embedded structs are inlined unless the tag names them, for yaml
+//     only when tagged with ",inline", as are other structs tagged so.
+func keyPath(path []pathElem, tag string) []string {
+	keys := make([]string, 0, len(path))
+
+	for i, elem := range path {
+		value, _ := elem.tag.Lookup(tag)
+		if value == "-" {
+			return nil
+		}
+
+		name, opts, _ := strings.Cut(value, ",")
+
+		inline := hasOpt(opts, "inline") || elem.anonymous && name == "" && tag != "yaml"
+		if inline && i < len(path)-1 {
+			continue
+		}
+
+		if name == "" {
+			name = elem.name
+			if tag == "yaml" {
+				name = strings.ToLower(name)
+			}
+		}
+
+		keys = append(keys, name)
+	}
+
+	return keys
+}
+
+func hasOpt(opts string, opt string) bool {
+	for opts != "" {
+		var o string
+		o, opts, _ = strings.Cut(opts, ",")
+		if o == opt {
+			return true
+		}
+	}
+	return false
+}
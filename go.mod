@@ -13,5 +13,8 @@ go 1.21
 require (
 	github.com/google/go-cmp v0.5.8
 	golang.org/x/exp v0.0.0-20240325151524-a685a6edb6d8
+	golang.org/x/term v0.15.0
 	golang.org/x/text v0.14.0
 )
+
+require golang.org/x/sys v0.15.0 // indirect
@@ -0,0 +1,138 @@
+package uconfig
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+
+	"github.com/omeid/uconfig/flat"
+)
+
+// Live is a handle to a config that is kept up to date in the
+// background, see Config.Live. It is safe for concurrent use.
+type Live[C any] struct {
+	conf atomic.Pointer[C]
+
+	mu   sync.Mutex
+	subs map[string][]func(old any, new any)
+
+	done chan struct{}
+	err  error
+}
+
+// Load returns the current config. The returned value is never
+// modified, a reload replaces it with a new one instead.
+func (l *Live[C]) Load() *C {
+	return l.conf.Load()
+}
+
+// Subscribe calls fn with the old and new value of the field every
+// time a reload changes it. The field is the flat name of the field,
+// e.g. Redis.Port. fn is called after Load returns the new config,
+// by the goroutine that reloads it, so the next reload waits for fn.
+func (l *Live[C]) Subscribe(field string, fn func(old any, new any)) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.subs[field] = append(l.subs[field], fn)
+}
+
+// Done is closed once the config is no longer kept up to date,
+// either because the context is cancelled or watching failed.
+func (l *Live[C]) Done() <-chan struct{} {
+	return l.done
+}
+
+// Err returns the reason the config is no longer kept up to date,
+// it is nil until Done is closed.
+func (l *Live[C]) Err() error {
+	select {
+	case <-l.done:
+		return l.err
+	default:
+		return nil
+	}
+}
+
+func (l *Live[C]) store(old *C, new *C, changes []Change) {
+	l.conf.Store(new)
+
+	if old == nil || len(changes) == 0 {
+		return
+	}
+
+	// the callbacks are called without holding the lock, so that
+	// they can subscribe too.
+	l.mu.Lock()
+	subs := make(map[string][]func(old any, new any), len(changes))
+	for _, change := range changes {
+		if fns := l.subs[change.Field]; len(fns) > 0 {
+			subs[change.Field] = fns[:len(fns):len(fns)]
+		}
+	}
+	l.mu.Unlock()
+
+	if len(subs) == 0 {
+		return
+	}
+
+	oldValues, newValues := fieldValues(old), fieldValues(new)
+	for _, change := range changes {
+		for _, fn := range subs[change.Field] {
+			fn(oldValues[change.Field], newValues[change.Field])
+		}
+	}
+}
+
+// fieldValues returns the value of the config fields by name.
+func fieldValues(conf any) map[string]any {
+	fields, err := flat.View(conf)
+	if err != nil {
+		return nil
+	}
+
+	values := make(map[string]any, len(fields))
+	for _, f := range fields {
+		name, _ := f.Name("")
+		values[name] = f.Interface()
+	}
+
+	return values
+}
+
+// Live parses the config and keeps it up to date in the background
+// whenever any plugin that implements Updater signals a change, until
+// ctx is done. Unlike Watch, there is no callback to restart, readers
+// call Load to get the current config. Failed re-parses and changes to
+// fields tagged with reload:"restart" leave the current config in place.
+// The config is closed once the Live is done.
+func (c *config[C]) Live(ctx context.Context) (*Live[C], error) {
+	conf, fields, err := c.parse(ctx)
+	if err != nil {
+		return nil, c.closeWith(err)
+	}
+
+	live := &Live[C]{
+		subs: map[string][]func(old any, new any){},
+		done: make(chan struct{}),
+	}
+	live.conf.Store(conf)
+
+	opts := WatchOptions[C]{
+		OnHotReload:       live.store,
+		OnRestartRequired: func([]Change) error { return nil },
+	}
+
+	go func() {
+		defer close(live.done)
+
+		err := c.watchFrom(ctx, conf, fields, opts, true, func(ctx context.Context, old *C, new *C, changes []Change) error {
+			live.store(old, new, changes)
+			<-ctx.Done()
+			return nil
+		})
+		live.err = c.closeWith(err)
+	}()
+
+	return live, nil
+}
@@ -0,0 +1,129 @@
+package uconfig_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/omeid/uconfig"
+)
+
+func TestLive(t *testing.T) {
+	source := newSourceUpdater(map[string]string{"Level": "info", "Port": "80"})
+
+	conf := uconfig.New[watchConfig](source)
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	live, err := conf.Live(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	first := live.Load()
+	if first.Port != 80 {
+		t.Fatalf("expected 80, got %d", first.Port)
+	}
+
+	type update struct{ old, new any }
+	ports := make(chan update, 5)
+	live.Subscribe("Port", func(old, new any) {
+		ports <- update{old, new}
+	})
+
+	source.set("Port", "8080")
+
+	select {
+	case u := <-ports:
+		if u.old != 80 || u.new != 8080 {
+			t.Errorf("expected 80 -> 8080, got %v -> %v", u.old, u.new)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("timeout waiting for subscription")
+	}
+
+	if port := live.Load().Port; port != 8080 {
+		t.Errorf("expected 8080, got %d", port)
+	}
+
+	if first.Port != 80 {
+		t.Errorf("expected the first snapshot to be untouched, got %d", first.Port)
+	}
+
+	// a failed reload keeps the current config.
+	source.set("Port", "bad")
+
+	select {
+	case u := <-ports:
+		t.Errorf("unexpected port update: %v -> %v", u.old, u.new)
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	if port := live.Load().Port; port != 8080 {
+		t.Errorf("expected 8080, got %d", port)
+	}
+
+	cancel()
+
+	select {
+	case <-live.Done():
+	case <-time.After(2 * time.Second):
+		t.Fatal("Live didn't stop after cancel")
+	}
+
+	if err := live.Err(); !errors.Is(err, context.Canceled) {
+		t.Errorf("expected context.Canceled, got %v", err)
+	}
+}
+
+func TestLiveParseError(t *testing.T) {
+	source := newSourceUpdater(map[string]string{"Port": "bad"})
+
+	conf := uconfig.New[watchConfig](source)
+
+	_, err := conf.Live(context.Background())
+	if err == nil {
+		t.Fatal("expected error, got nil")
+	}
+}
+
+func TestLiveSubscribeFromCallback(t *testing.T) {
+	source := newSourceUpdater(map[string]string{"Level": "info", "Port": "80"})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	live, err := uconfig.New[watchConfig](source).Live(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	subscribed := make(chan struct{}, 5)
+	levels := make(chan any, 5)
+	live.Subscribe("Port", func(old, new any) {
+		live.Subscribe("Level", func(old, new any) {
+			levels <- new
+		})
+		subscribed <- struct{}{}
+	})
+
+	source.set("Port", "8080")
+
+	select {
+	case <-subscribed:
+	case <-time.After(2 * time.Second):
+		t.Fatal("timeout waiting for the subscription")
+	}
+
+	source.set("Level", "debug")
+
+	select {
+	case level := <-levels:
+		if level != "debug" {
+			t.Errorf("expected debug, got %v", level)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("timeout waiting for the nested subscription")
+	}
+}
@@ -1,6 +1,7 @@
 package uconfig
 
 import (
+	"github.com/omeid/uconfig/flat"
 	"github.com/omeid/uconfig/plugins"
 	"github.com/omeid/uconfig/plugins/defaults"
 	"github.com/omeid/uconfig/plugins/env"
@@ -29,3 +30,17 @@ func Load[C any](files PluginProvider, userPlugins ...plugins.Plugin) Config[C]
 
 	return New[C](ps...)
 }
+
+// EnvPrefix returns a plugin that prefixes the env names of the fields
+// without an explicit env tag, like env.WithPrefix, for the env plugin
+// of Load and Classic.
+func EnvPrefix(prefix string) plugins.Plugin {
+	return extend(func(p env.Prefixer) { p.SetPrefix(prefix) })
+}
+
+// EnvNaming returns a plugin that names the env vars of the fields
+// without an explicit env tag, like env.WithNaming, for the env plugin
+// of Load and Classic.
+func EnvNaming(naming flat.Naming) plugins.Plugin {
+	return extend(func(n env.Namer) { n.SetNaming(naming) })
+}
@@ -1,15 +1,19 @@
 package uconfig_test
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
 	"os"
+	"strings"
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
 	"github.com/omeid/uconfig"
+	"github.com/omeid/uconfig/flat"
 	"github.com/omeid/uconfig/internal/f"
 	"github.com/omeid/uconfig/plugins/file"
+	"github.com/omeid/uconfig/plugins/flag"
 	"github.com/omeid/uconfig/plugins/secret"
 )
 
@@ -193,3 +197,72 @@ func TestLoadBadPlugin(t *testing.T) {
 		t.Errorf("Expected unsupported plugin error, got: %v", err)
 	}
 }
+
+func TestLoadEnvPrefix(t *testing.T) {
+	type Config struct {
+		Port int
+		Home string `env:"HOME_DIR"`
+	}
+
+	t.Setenv("MYAPP_PORT", "8080")
+	t.Setenv("PORT", "9090")
+	t.Setenv("HOME_DIR", "/home/app")
+
+	conf := uconfig.Load[Config](nil, uconfig.EnvPrefix("MYAPP_"))
+
+	value, err := conf.Parse()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if diff := cmp.Diff(&Config{Port: 8080, Home: "/home/app"}, value); diff != "" {
+		t.Error(diff)
+	}
+
+	var out bytes.Buffer
+	err = conf.WriteUsage(&out, uconfig.UsageText)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, expect := range []string{"MYAPP_PORT", "HOME_DIR"} {
+		if !strings.Contains(out.String(), expect) {
+			t.Errorf("expected %q in the usage:\n%s", expect, out.String())
+		}
+	}
+}
+
+func TestLoadNaming(t *testing.T) {
+	type Config struct {
+		MaxConns int
+		ReadOnly bool
+	}
+
+	t.Setenv("MAX_CONNS", "10")
+
+	conf := uconfig.Load[Config](nil, uconfig.EnvNaming(flat.ScreamingSnakeCase))
+
+	value, err := conf.Parse()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if value.MaxConns != 10 {
+		t.Errorf("expected MAX_CONNS to set MaxConns, got %d", value.MaxConns)
+	}
+
+	// as Classic has it, but with the test args.
+	conf = uconfig.New[Config](
+		uconfig.FlagNaming(flat.KebabCase),
+		flag.New("testing", flag.ContinueOnError, []string{"-read-only"}),
+	)
+
+	value, err = conf.Parse()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !value.ReadOnly {
+		t.Error("expected -read-only to set ReadOnly")
+	}
+}
@@ -2,6 +2,8 @@
 package defaults
 
 import (
+	"errors"
+
 	"github.com/omeid/uconfig/flat"
 	"github.com/omeid/uconfig/plugins"
 )
@@ -35,16 +37,16 @@ func (v *visitor) Visit(f flat.Fields) error {
 }
 
 func (v *visitor) Parse() error {
+	var errs error
+
 	for _, f := range v.fields {
 		value, ok := f.Meta()[tag]
 		if !ok {
 			continue
 		}
-		err := f.Set(value)
-		if err != nil {
-			return err
-		}
+		err := f.SetFrom(flat.Source{Plugin: tag, Value: value})
+		errs = errors.Join(errs, err)
 	}
 
-	return nil
+	return errs
 }
@@ -0,0 +1,192 @@
+// Package dotenv provides .env file support for uconfig.
+package dotenv
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/omeid/uconfig/flat"
+	"github.com/omeid/uconfig/plugins"
+	"github.com/omeid/uconfig/plugins/env"
+)
+
+const pluginName = "dotenv"
+
+// Config describes how the files are read.
+type Config struct {
+	// Optional ignores the files that do not exist.
+	Optional bool
+
+	// Override gives the variables of the files precedence over
+	// the environment, which otherwise takes precedence over them.
+	// The plugin must then be registered after the env plugin.
+	Override bool
+
+	// Setenv sets the variables in the environment of the process for
+	// the env plugin, and anything else, to read instead of setting the
+	// fields, the plugin must then be registered before the env plugin.
+	// Later parses overwrite the variables the plugin set and restore
+	// those removed from the files, so that a reload reads the changed
+	// files.
+	Setenv bool
+}
+
+// New returns a plugin that reads the variables of the .env files at
+// the paths, later files take precedence over earlier ones. The fields
+// are named like the env plugin names them, including its prefix and
+// naming when it is registered, so the variables of a .env file are
+// those the program would read from the environment.
+func New(config Config, paths ...string) plugins.Plugin {
+	return &visitor{config: config, paths: paths}
+}
+
+var _ plugins.Visitor = (*visitor)(nil)
+
+type visitor struct {
+	config Config
+	paths  []string
+	fields flat.Fields
+
+	// set holds the variables Setenv set, which are not the environment
+	// of the process, so that later parses read the files again, with
+	// what the environment had before the plugin set them.
+	set map[string]original
+}
+
+// original is the value of a variable before the plugin set it.
+type original struct {
+	value string
+	ok    bool
+}
+
+// FileNames lists the files in the configuration files of Usage.
+func (v *visitor) FileNames() []string {
+	return v.paths
+}
+
+// lookupEnv looks a variable up in the environment as it was before
+// the plugin set it.
+func (v *visitor) lookupEnv(name string) (string, bool) {
+	if orig, ok := v.set[name]; ok {
+		return orig.value, orig.ok
+	}
+	return os.LookupEnv(name)
+}
+
+func (v *visitor) Visit(fields flat.Fields) error {
+	v.fields = fields
+	return nil
+}
+
+// source is where a variable was read from.
+type source struct {
+	value string
+	path  string
+}
+
+// read reads the files, the variables of later files take
+// precedence over those of earlier ones.
+func (v *visitor) read() (map[string]source, error) {
+	vars := map[string]source{}
+
+	lookup := func(name string) (string, bool) {
+		if v.config.Override {
+			if src, ok := vars[name]; ok {
+				return src.value, true
+			}
+		}
+
+		if value, ok := v.lookupEnv(name); ok {
+			return value, true
+		}
+
+		src, ok := vars[name]
+		return src.value, ok
+	}
+
+	for _, path := range v.paths {
+		data, err := os.ReadFile(path)
+		if errors.Is(err, os.ErrNotExist) && v.config.Optional {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		parsed, err := parse(string(data), lookup, v.config.Override)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+
+		for _, variable := range parsed {
+			vars[variable.name] = source{value: variable.value, path: path}
+		}
+	}
+
+	return vars, nil
+}
+
+func (v *visitor) Parse() error {
+	vars, err := v.read()
+	if err != nil {
+		return err
+	}
+
+	if v.config.Setenv {
+		// restore the variables removed from the files since the last parse.
+		for name, orig := range v.set {
+			if _, ok := vars[name]; ok {
+				continue
+			}
+
+			if orig.ok {
+				err = errors.Join(err, os.Setenv(name, orig.value))
+			} else {
+				err = errors.Join(err, os.Unsetenv(name))
+			}
+			delete(v.set, name)
+		}
+
+		for name, src := range vars {
+			value, ok := v.lookupEnv(name)
+			if ok && !v.config.Override {
+				continue
+			}
+
+			if _, set := v.set[name]; !set {
+				if v.set == nil {
+					v.set = map[string]original{}
+				}
+				v.set[name] = original{value: value, ok: ok}
+			}
+			err = errors.Join(err, os.Setenv(name, src.value))
+		}
+
+		return err
+	}
+
+	var errs error
+
+	for _, f := range v.fields {
+		name := env.Name(f)
+		if name == "-" {
+			continue
+		}
+
+		src, ok := vars[name]
+		if !ok {
+			continue
+		}
+
+		// the environment takes precedence, whatever the plugin order.
+		if _, ok := v.lookupEnv(name); ok && !v.config.Override {
+			continue
+		}
+
+		err := f.SetFrom(flat.Source{Plugin: pluginName, Key: src.path + ":" + name, Value: src.value})
+		errs = errors.Join(errs, err)
+	}
+
+	return errs
+}
@@ -0,0 +1,258 @@
+package dotenv_test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/omeid/uconfig"
+	"github.com/omeid/uconfig/plugins/defaults"
+	"github.com/omeid/uconfig/plugins/dotenv"
+	"github.com/omeid/uconfig/plugins/env"
+)
+
+type Config struct {
+	Name  string `default:"default"`
+	Port  int
+	Debug bool
+	DB    struct {
+		Host string
+	}
+}
+
+func write(t *testing.T, name string, content string) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), name)
+	err := os.WriteFile(path, []byte(content), 0o600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestDotenvFields(t *testing.T) {
+	base := write(t, ".env", "NAME=base\nPORT=80\nDB_HOST=db\n")
+	local := write(t, ".env.local", "PORT=8080\n")
+
+	t.Setenv("DEBUG", "true")
+
+	conf := uconfig.New[Config](
+		defaults.New(),
+		env.New(),
+		dotenv.New(dotenv.Config{}, base, local),
+	)
+
+	value, err := conf.Parse()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expect := &Config{Name: "base", Port: 8080, Debug: true}
+	expect.DB.Host = "db"
+
+	if diff := cmp.Diff(expect, value); diff != "" {
+		t.Error(diff)
+	}
+
+	sources := conf.Sources()["Port"]
+	last := sources[len(sources)-1]
+	if last.Plugin != "dotenv" || last.Key != local+":PORT" {
+		t.Errorf("expected the port from %s, got %+v", local, last)
+	}
+}
+
+func TestDotenvPrecedence(t *testing.T) {
+	path := write(t, ".env", "NAME=dotenv\nPORT=80\n")
+
+	t.Setenv("NAME", "env")
+
+	tests := []struct {
+		override bool
+		expect   string
+	}{
+		{false, "env"},
+		{true, "dotenv"},
+	}
+
+	for _, tt := range tests {
+		conf := uconfig.New[Config](
+			env.New(),
+			dotenv.New(dotenv.Config{Override: tt.override}, path),
+		)
+
+		value, err := conf.Parse()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if value.Name != tt.expect || value.Port != 80 {
+			t.Errorf("override %v: expected %s, got %+v", tt.override, tt.expect, value)
+		}
+	}
+}
+
+func TestDotenvPrefix(t *testing.T) {
+	path := write(t, ".env", "export APP_PORT=8080\nPORT=80\n")
+
+	conf := uconfig.New[Config](
+		env.New(env.WithPrefix("APP_")),
+		dotenv.New(dotenv.Config{}, path),
+	)
+
+	value, err := conf.Parse()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if value.Port != 8080 {
+		t.Errorf("expected the prefixed port, got %d", value.Port)
+	}
+}
+
+func TestDotenvSetenv(t *testing.T) {
+	path := write(t, ".env", "NAME=dotenv\nDB_HOST=${NAME}-db\n")
+
+	// register both so that t restores them.
+	t.Setenv("NAME", "env")
+	t.Setenv("DB_HOST", "")
+	os.Unsetenv("DB_HOST")
+
+	conf := uconfig.New[Config](
+		dotenv.New(dotenv.Config{Setenv: true}, path),
+		env.New(),
+	)
+
+	value, err := conf.Parse()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if value.Name != "env" || value.DB.Host != "env-db" {
+		t.Errorf("expected the environment to take precedence, got %+v", value)
+	}
+
+	if got := os.Getenv("DB_HOST"); got != "env-db" {
+		t.Errorf("expected DB_HOST to be set, got %q", got)
+	}
+}
+
+func TestDotenvSetenvReload(t *testing.T) {
+	path := write(t, ".env", "NAME=info\nPORT=80\n")
+
+	// register both so that t restores them.
+	for _, name := range []string{"NAME", "PORT"} {
+		t.Setenv(name, "")
+		os.Unsetenv(name)
+	}
+
+	conf := uconfig.New[Config](
+		dotenv.New(dotenv.Config{Setenv: true}, path),
+		env.New(),
+	)
+
+	value, err := conf.Parse()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if value.Name != "info" || value.Port != 80 {
+		t.Fatalf("expected the values of the file, got %+v", value)
+	}
+
+	err = os.WriteFile(path, []byte("NAME=debug\n"), 0o600)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	value, err = conf.Parse()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if value.Name != "debug" || value.Port != 0 {
+		t.Errorf("expected the changed file to be read, got %+v", value)
+	}
+
+	if _, ok := os.LookupEnv("PORT"); ok {
+		t.Error("expected PORT to be unset once removed from the file")
+	}
+}
+
+func TestDotenvSetenvRestore(t *testing.T) {
+	path := write(t, ".env", "NAME=dotenv\n")
+
+	t.Setenv("NAME", "env")
+
+	// register PORT so that t restores it.
+	t.Setenv("PORT", "")
+	os.Unsetenv("PORT")
+
+	conf := uconfig.New[Config](
+		dotenv.New(dotenv.Config{Setenv: true, Override: true}, path),
+		env.New(),
+	)
+
+	value, err := conf.Parse()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if value.Name != "dotenv" {
+		t.Fatalf("expected the file to override the environment, got %q", value.Name)
+	}
+
+	err = os.WriteFile(path, []byte("PORT=80\n"), 0o600)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	value, err = conf.Parse()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if value.Name != "env" || os.Getenv("NAME") != "env" {
+		t.Errorf("expected NAME to be restored once removed from the file, got %q", value.Name)
+	}
+}
+
+func TestDotenvMissing(t *testing.T) {
+	missing := filepath.Join(t.TempDir(), ".env")
+
+	_, err := uconfig.New[Config](dotenv.New(dotenv.Config{Optional: true}, missing)).Parse()
+	if err != nil {
+		t.Errorf("expected optional files to be ignored, got %v", err)
+	}
+
+	_, err = uconfig.New[Config](dotenv.New(dotenv.Config{}, missing)).Parse()
+	if !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("expected a missing file error, got %v", err)
+	}
+
+	bad := write(t, ".env", "PORT=80\nnot a variable\n")
+	_, err = uconfig.New[Config](dotenv.New(dotenv.Config{}, bad)).Parse()
+	expect := bad + ": line 2: expected NAME=value"
+	if err == nil || !strings.Contains(err.Error(), expect) {
+		t.Errorf("expected (%s) but got (%v)", expect, err)
+	}
+}
+
+func TestDotenvUsage(t *testing.T) {
+	conf := uconfig.New[Config](dotenv.New(dotenv.Config{Optional: true}, ".env", ".env.local"))
+	_, _ = conf.Parse()
+
+	var out strings.Builder
+	err := conf.WriteUsage(&out, uconfig.UsageText)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expect := "Configuration Files:\n    .env\n    .env.local\n"
+	if !strings.HasSuffix(out.String(), expect) {
+		t.Errorf("expected the files to be listed, got:\n%s", out.String())
+	}
+}
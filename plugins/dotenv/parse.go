@@ -0,0 +1,209 @@
+package dotenv
+
+import (
+	"fmt"
+	"strings"
+)
+
+// variable is a variable of a dotenv file.
+type variable struct {
+	name  string
+	value string
+	line  int
+}
+
+// parse reads the variables of a dotenv file, the references to other
+// variables are expanded with those defined before them, or lookup. The
+// variables of lookup take precedence unless override is set.
+//
+//	# comments and blank lines are ignored.
+//	export NAME=value        # export is optional, and so is this comment.
+//	NAME='literal ${VALUE}'  # no escapes or expansion, may span lines.
+//	NAME="line\n${OTHER}"    # escapes and expansion, may span lines.
+//	NAME=${OTHER:-default}   # the default is used when OTHER is unset or empty.
+func parse(src string, lookup func(name string) (string, bool), override bool) ([]variable, error) {
+	var vars []variable
+
+	defined := map[string]string{}
+	lookupVar := func(name string) (string, bool) {
+		if !override {
+			if value, ok := lookup(name); ok {
+				return value, true
+			}
+		}
+
+		if value, ok := defined[name]; ok {
+			return value, true
+		}
+		return lookup(name)
+	}
+
+	src = strings.ReplaceAll(src, "\r\n", "\n")
+	line := 1
+
+	for len(src) > 0 {
+		var current string
+		current, src, _ = strings.Cut(src, "\n")
+		start := line
+		line++
+
+		current = strings.TrimSpace(current)
+		if current == "" || strings.HasPrefix(current, "#") {
+			continue
+		}
+
+		current = strings.TrimPrefix(current, "export ")
+
+		name, rest, ok := strings.Cut(current, "=")
+		name = strings.TrimSpace(name)
+		if !ok || !validName(name) {
+			return nil, fmt.Errorf("line %d: expected NAME=value", start)
+		}
+
+		rest = strings.TrimLeft(rest, " \t")
+
+		var value string
+		switch {
+		case strings.HasPrefix(rest, "'"), strings.HasPrefix(rest, `"`):
+			quote := rest[:1]
+
+			// the value may span lines until the closing quote.
+			body := rest[1:]
+			for {
+				end := closingQuote(body, quote)
+				if end >= 0 {
+					value, rest = body[:end], body[end+1:]
+					break
+				}
+
+				if src == "" {
+					return nil, fmt.Errorf("line %d: unterminated %s quote", start, quote)
+				}
+
+				var next string
+				next, src, _ = strings.Cut(src, "\n")
+				body += "\n" + next
+				line++
+			}
+
+			rest = strings.TrimSpace(rest)
+			if rest != "" && !strings.HasPrefix(rest, "#") {
+				return nil, fmt.Errorf("line %d: unexpected %q after the value", start, rest)
+			}
+
+			if quote == `"` {
+				value = expand(unescape(value), lookupVar)
+			}
+
+		default:
+			value = rest
+			if i := strings.Index(value, " #"); i >= 0 {
+				value = value[:i]
+			}
+			value = expand(strings.TrimSpace(value), lookupVar)
+		}
+
+		defined[name] = value
+		vars = append(vars, variable{name: name, value: value, line: start})
+	}
+
+	return vars, nil
+}
+
+func validName(name string) bool {
+	if name == "" {
+		return false
+	}
+
+	for i, r := range name {
+		switch {
+		case r == '_', r == '.', r >= 'A' && r <= 'Z', r >= 'a' && r <= 'z':
+		case r >= '0' && r <= '9' && i > 0:
+		default:
+			return false
+		}
+	}
+
+	return true
+}
+
+// closingQuote returns the index of the quote that closes the value,
+// skipping escaped ones in double quotes, or -1.
+func closingQuote(value string, quote string) int {
+	for i := 0; i < len(value); i++ {
+		switch {
+		case value[i] == '\\' && quote == `"`:
+			i++
+		case value[i:i+1] == quote:
+			return i
+		}
+	}
+
+	return -1
+}
+
+var escapes = strings.NewReplacer(
+	`\n`, "\n",
+	`\r`, "\r",
+	`\t`, "\t",
+	`\"`, `"`,
+	`\\`, `\`,
+	`\$`, "\x00",
+)
+
+// unescape replaces the escapes of a double quoted value, an escaped
+// $ is kept from expansion as a NUL until expand puts it back.
+func unescape(value string) string {
+	return escapes.Replace(value)
+}
+
+// expand replaces ${NAME}, ${NAME:-default}, and $NAME with the value
+// of the variable, or empty if it is not set.
+func expand(value string, lookup func(name string) (string, bool)) string {
+	var b strings.Builder
+
+	for i := 0; i < len(value); i++ {
+		if value[i] != '$' || i+1 == len(value) {
+			b.WriteByte(value[i])
+			continue
+		}
+
+		if value[i+1] == '{' {
+			end := strings.IndexByte(value[i:], '}')
+			if end < 0 {
+				b.WriteByte(value[i])
+				continue
+			}
+
+			name, fallback, hasDefault := strings.Cut(value[i+2:i+end], ":-")
+			resolved, _ := lookup(name)
+			if resolved == "" && hasDefault {
+				resolved = fallback
+			}
+
+			b.WriteString(resolved)
+			i += end
+			continue
+		}
+
+		end := i + 1
+		for end < len(value) && isNameByte(value[end], end == i+1) {
+			end++
+		}
+
+		if end == i+1 {
+			b.WriteByte(value[i])
+			continue
+		}
+
+		resolved, _ := lookup(value[i+1 : end])
+		b.WriteString(resolved)
+		i = end - 1
+	}
+
+	return strings.ReplaceAll(b.String(), "\x00", "$")
+}
+
+func isNameByte(c byte, first bool) bool {
+	return c == '_' || c >= 'A' && c <= 'Z' || c >= 'a' && c <= 'z' || !first && c >= '0' && c <= '9'
+}
@@ -0,0 +1,92 @@
+package dotenv
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestParse(t *testing.T) {
+	src := `# a comment
+export NAME=uconfig
+PLAIN = some value # inline comment
+HASH=a#b
+SINGLE='literal ${NAME}\n'
+DOUBLE="${NAME}\tand \"quotes\" \$NAME"
+MULTI="first
+second"
+REF=$NAME-${HOME}
+DEFAULT=${MISSING:-fallback}
+EMPTY=
+`
+
+	env := map[string]string{"HOME": "/home/me", "NAME": "env"}
+	lookup := func(name string) (string, bool) {
+		value, ok := env[name]
+		return value, ok
+	}
+
+	tests := []struct {
+		override bool
+		expect   map[string]string
+	}{
+		{false, map[string]string{
+			"NAME":    "uconfig",
+			"PLAIN":   "some value",
+			"HASH":    "a#b",
+			"SINGLE":  `literal ${NAME}\n`,
+			"DOUBLE":  "env\tand \"quotes\" $NAME",
+			"MULTI":   "first\nsecond",
+			"REF":     "env-/home/me",
+			"DEFAULT": "fallback",
+			"EMPTY":   "",
+		}},
+		{true, map[string]string{
+			"NAME":    "uconfig",
+			"PLAIN":   "some value",
+			"HASH":    "a#b",
+			"SINGLE":  `literal ${NAME}\n`,
+			"DOUBLE":  "uconfig\tand \"quotes\" $NAME",
+			"MULTI":   "first\nsecond",
+			"REF":     "uconfig-/home/me",
+			"DEFAULT": "fallback",
+			"EMPTY":   "",
+		}},
+	}
+
+	for _, tt := range tests {
+		vars, err := parse(src, lookup, tt.override)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		got := map[string]string{}
+		for _, v := range vars {
+			got[v.name] = v.value
+		}
+
+		if diff := cmp.Diff(tt.expect, got); diff != "" {
+			t.Errorf("override %v: %s", tt.override, diff)
+		}
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	none := func(string) (string, bool) { return "", false }
+
+	tests := []struct {
+		src    string
+		expect string
+	}{
+		{"NAME=ok\nnot a variable", "line 2: expected NAME=value"},
+		{"1NAME=value", "line 1: expected NAME=value"},
+		{"\nNAME=\"open\nstill open", `line 2: unterminated " quote`},
+	}
+
+	for _, tt := range tests {
+		_, err := parse(tt.src, none, false)
+		if err == nil || err.Error() != tt.expect {
+			t.Errorf("%q: expected (%s) but got (%v)", tt.src, tt.expect, err)
+		}
+	}
+}
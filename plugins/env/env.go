@@ -2,6 +2,9 @@
 package env
 
 import (
+	"errors"
+	"fmt"
+	"io/fs"
 	"os"
 	"strings"
 
@@ -9,19 +12,106 @@ import (
 	"github.com/omeid/uconfig/plugins"
 )
 
-const tag = "env"
+const (
+	tag = "env"
+
+	// fileMeta is the meta key of the env var that names a
+	// file to read the value from, see WithFiles.
+	fileMeta = "env_file"
+
+	fileSuffix = "_FILE"
+)
 
 func init() {
 	plugins.RegisterTag(tag)
 }
 
+// Option configures the env plugin.
+type Option func(*visitor)
+
+// WithPrefix prefixes the names of the fields that have no explicit
+// env tag, e.g. with MYAPP_, Redis.Host is read from MYAPP_REDIS_HOST.
+func WithPrefix(prefix string) Option {
+	return func(v *visitor) {
+		v.prefix = prefix
+	}
+}
+
+// WithNaming names the fields that have no explicit env tag, e.g.
+// flat.ScreamingSnakeCase reads Redis.MaxConns from REDIS_MAX_CONNS
+// instead of REDIS_MAXCONNS.
+func WithNaming(naming flat.Naming) Option {
+	return func(v *visitor) {
+		v.naming = naming
+	}
+}
+
+// WithFiles also reads the value of every field from the file named by
+// the env var with the _FILE suffix, e.g. DB_PASSWORD from the file at
+// DB_PASSWORD_FILE, the way Docker and Kubernetes secrets are commonly
+// passed. The contents are trimmed of surrounding white space, and
+// setting both env vars is an error.
+func WithFiles() Option {
+	return func(v *visitor) {
+		v.files = true
+	}
+}
+
 // New returns an env plugin.
-func New() plugins.Plugin {
-	return &visitor{}
+func New(opts ...Option) plugins.Plugin {
+	v := &visitor{naming: makeEnvName}
+	for _, opt := range opts {
+		opt(v)
+	}
+	return v
 }
 
+// Prefixer is implemented by the env plugin for extensions to
+// set the prefix of plugins they have not created, like WithPrefix.
+type Prefixer interface {
+	SetPrefix(prefix string)
+}
+
+// Namer is implemented by the env plugin for extensions to
+// set the naming of plugins they have not created, like WithNaming.
+type Namer interface {
+	SetNaming(naming flat.Naming)
+}
+
+var (
+	_ Prefixer = (*visitor)(nil)
+	_ Namer    = (*visitor)(nil)
+)
+
 type visitor struct {
 	fields flat.Fields
+	prefix string
+	naming flat.Naming
+	files  bool
+}
+
+func (v *visitor) SetPrefix(prefix string) {
+	v.prefix = prefix
+}
+
+func (v *visitor) SetNaming(naming flat.Naming) {
+	v.naming = naming
+}
+
+// Name returns the env var name of the field as the env plugin has
+// named it, or as it names fields by default if it has not visited
+// the field, it is "-" for fields that are not read from the env.
+func Name(f flat.Field) string {
+	if name, ok := f.Meta()[tag]; ok {
+		return name
+	}
+
+	name, explicit := f.Name(tag)
+	if explicit {
+		return name
+	}
+
+	return makeEnvName(name)
 }
 
 func makeEnvName(name string) string {
@@ -36,16 +126,22 @@ func (v *visitor) Visit(f flat.Fields) error {
 	for _, f := range v.fields {
 		name, explicit := f.Name(tag)
 		if !explicit {
-			name = makeEnvName(name)
+			name = v.prefix + v.naming(name)
 		}
 
 		f.Meta()[tag] = name
+
+		if v.files && name != "-" {
+			f.Meta()[fileMeta] = name + fileSuffix
+		}
 	}
 
 	return nil
 }
 
 func (v *visitor) Parse() error {
+	var errs error
+
 	for _, f := range v.fields {
 
 		name := f.Meta()[tag]
@@ -55,15 +151,55 @@ func (v *visitor) Parse() error {
 		}
 
 		value, ok := os.LookupEnv(name)
+
+		if v.files {
+			path, isFile := os.LookupEnv(name + fileSuffix)
+			if isFile && ok {
+				errs = errors.Join(errs, fieldError(f, name+fileSuffix, path,
+					fmt.Errorf("%s is also set", name)))
+				continue
+			}
+
+			if isFile {
+				errs = errors.Join(errs, readFile(f, name+fileSuffix, path))
+				continue
+			}
+		}
+
 		if !ok {
 			continue
 		}
 
-		err := f.Set(value)
-		if err != nil {
-			return err
+		err := f.SetFrom(flat.Source{Plugin: tag, Key: name, Value: value})
+		errs = errors.Join(errs, err)
+	}
+
+	return errs
+}
+
+// readFile sets the field to the trimmed contents of the file at path,
+// the source is the env var and the path, e.g. DB_PASSWORD_FILE=/run/secrets/db.
+func readFile(f flat.Field, name string, path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		// the path is already in the key.
+		var pathErr *fs.PathError
+		if errors.As(err, &pathErr) {
+			err = pathErr.Err
 		}
+		return fieldError(f, name, path, err)
 	}
 
-	return nil
+	value := strings.TrimSpace(string(data))
+	return f.SetFrom(flat.Source{Plugin: tag, Key: name + "=" + path, Value: value})
+}
+
+func fieldError(f flat.Field, name string, path string, err error) error {
+	field, _ := f.Name("")
+	return &flat.FieldError{
+		Field:  field,
+		Plugin: tag,
+		Key:    name + "=" + path,
+		Err:    err,
+	}
 }
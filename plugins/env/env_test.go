@@ -2,10 +2,13 @@ package env_test
 
 import (
 	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
 	"github.com/omeid/uconfig"
+	"github.com/omeid/uconfig/flat"
 	"github.com/omeid/uconfig/internal/f"
 	"github.com/omeid/uconfig/plugins/env"
 )
@@ -94,3 +97,121 @@ func TestEnvTag(t *testing.T) {
 		t.Error(diff)
 	}
 }
+
+func TestEnvPrefix(t *testing.T) {
+	type Config struct {
+		Port  int
+		Home  string `env:"HOME_DIR"`
+		Redis struct {
+			Host string
+		}
+	}
+
+	t.Setenv("MYAPP_PORT", "8080")
+	t.Setenv("PORT", "9090")
+	t.Setenv("HOME_DIR", "/home/app")
+	t.Setenv("MYAPP_REDIS_HOST", "redis")
+
+	expect := &Config{Port: 8080, Home: "/home/app"}
+	expect.Redis.Host = "redis"
+
+	conf := uconfig.New[Config](env.New(env.WithPrefix("MYAPP_")))
+
+	value, err := conf.Parse()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if diff := cmp.Diff(expect, value); diff != "" {
+		t.Error(diff)
+	}
+}
+
+func TestEnvNaming(t *testing.T) {
+	type Config struct {
+		MaxConns   int
+		HTTPServer struct {
+			ReadTimeout string
+		}
+		Home string `env:"HOME_DIR"`
+	}
+
+	t.Setenv("APP_MAX_CONNS", "10")
+	t.Setenv("APP_HTTP_SERVER_READ_TIMEOUT", "5s")
+	t.Setenv("HOME_DIR", "/home/app")
+
+	expect := &Config{MaxConns: 10, Home: "/home/app"}
+	expect.HTTPServer.ReadTimeout = "5s"
+
+	conf := uconfig.New[Config](env.New(env.WithPrefix("APP_"), env.WithNaming(flat.ScreamingSnakeCase)))
+
+	value, err := conf.Parse()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if diff := cmp.Diff(expect, value); diff != "" {
+		t.Error(diff)
+	}
+}
+
+func TestEnvFiles(t *testing.T) {
+	type Config struct {
+		User     string
+		Password string
+	}
+
+	path := filepath.Join(t.TempDir(), "password")
+	err := os.WriteFile(path, []byte("s3cret\n"), 0o600)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Setenv("USER", "admin")
+	t.Setenv("PASSWORD_FILE", path)
+
+	conf := uconfig.New[Config](env.New(env.WithFiles()))
+
+	value, err := conf.Parse()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expect := &Config{User: "admin", Password: "s3cret"}
+	if diff := cmp.Diff(expect, value); diff != "" {
+		t.Error(diff)
+	}
+
+	source := conf.Sources()["Password"][0]
+	if source.Key != "PASSWORD_FILE="+path {
+		t.Errorf("expected the source to name the file, got %+v", source)
+	}
+
+	var out strings.Builder
+	err = conf.WriteUsage(&out, uconfig.UsageText)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !strings.Contains(out.String(), "PASSWORD_FILE") {
+		t.Errorf("expected the usage to show PASSWORD_FILE, got:\n%s", out.String())
+	}
+
+	missing := filepath.Join(t.TempDir(), "missing")
+	t.Setenv("PASSWORD_FILE", missing)
+
+	_, err = conf.Parse()
+	expectErr := "Password (PASSWORD_FILE=" + missing + "): no such file or directory"
+	if err == nil || err.Error() != expectErr {
+		t.Errorf("expected (%s) but got (%v)", expectErr, err)
+	}
+
+	t.Setenv("PASSWORD_FILE", path)
+	t.Setenv("PASSWORD", "plain")
+
+	_, err = conf.Parse()
+	expectErr = "Password (PASSWORD_FILE=" + path + "): PASSWORD is also set"
+	if err == nil || err.Error() != expectErr {
+		t.Errorf("expected (%s) but got (%v)", expectErr, err)
+	}
+}
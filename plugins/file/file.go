@@ -2,10 +2,10 @@
 package file
 
 import (
-	"errors"
 	"io"
 	"os"
 
+	"github.com/omeid/uconfig/flat"
 	"github.com/omeid/uconfig/plugins"
 )
 
@@ -40,9 +40,11 @@ type Unmarshal func(src []byte, v any) error
 // NewReader returns a uconfig plugin that unmarshals the content of
 // the provided io.Reader into the config using the provided unmarshal
 // function. The src will be closed if it is an io.Closer.
+// The src is only read once, its content is reused on later parses.
 func NewReader(src io.Reader, filepath string, unmarshal Unmarshal) plugins.Plugin {
 	return &walker{
 		src:       src,
+		stream:    true,
 		name:      filepath,
 		filepath:  filepath,
 		unmarshal: unmarshal,
@@ -80,6 +82,12 @@ func FilePaths(ps []plugins.Plugin) []string {
 	return paths
 }
 
+// Named is implemented by plugins that read files other than those of
+// this package, like dotenv, so that FileNames lists their files too.
+type Named interface {
+	FileNames() []string
+}
+
 // FileNames returns the display names of file paths from a list of
 // plugins, filtering out non-file plugins. These are the names as
 // provided by the user, not resolved absolute paths.
@@ -88,6 +96,8 @@ func FileNames(ps []plugins.Plugin) []string {
 	for _, p := range ps {
 		if w, ok := p.(*walker); ok && w.name != "" {
 			names = append(names, w.name)
+		} else if n, ok := p.(Named); ok {
+			names = append(names, n.FileNames()...)
 		}
 	}
 	return names
@@ -98,9 +108,28 @@ type walker struct {
 	filepath  string        // resolved absolute path (set during Walk)
 	resolve   func() string // lazy resolver (from Path.Resolve)
 	src       io.Reader     // only set when created via NewReader
+	data      []byte        // content of src once read
+	stream    bool          // created via NewReader
 	conf      any
 	unmarshal Unmarshal
 	optional  bool
+	keys      [][]string // keys of the last parse
+}
+
+var (
+	_ plugins.Closer = (*walker)(nil)
+	_ plugins.Keyer  = (*walker)(nil)
+)
+
+func (w *walker) Keys() [][]string {
+	return w.keys
+}
+
+func (w *walker) Describe() (string, string) {
+	if w.filepath != "" {
+		return "file", w.filepath
+	}
+	return "file", w.name
 }
 
 func (w *walker) Walk(conf any) error {
@@ -112,7 +141,7 @@ func (w *walker) Walk(conf any) error {
 	}
 
 	// Check file exists early (for non-optional files).
-	if w.src == nil && w.filepath != "" {
+	if !w.stream && w.filepath != "" {
 		_, err := os.Stat(w.filepath)
 		if err != nil {
 			if w.optional && os.IsNotExist(err) {
@@ -126,41 +155,79 @@ func (w *walker) Walk(conf any) error {
 }
 
 func (w *walker) Parse() error {
-	var src io.Reader
-
-	if w.src != nil {
-		// Created via NewReader -- use the provided reader (one-shot).
-		src = w.src
-		w.src = nil // consumed
-	} else {
-		// Created via New -- open the file fresh each time.
-		f, err := os.Open(w.filepath)
-		if err != nil {
-			if w.optional && os.IsNotExist(err) {
-				return nil
+	w.keys = nil
+
+	data, err := w.read()
+	if err != nil || data == nil {
+		return err
+	}
+
+	err = w.unmarshal(data, w.conf)
+	if err != nil {
+		return &flat.FieldError{Plugin: "file", Key: w.filepath, Err: err}
+	}
+
+	w.keys = documentKeys(data, w.unmarshal)
+
+	return nil
+}
+
+// read returns the content of the file, or nil if the file is
+// optional and doesn't exist.
+func (w *walker) read() ([]byte, error) {
+	if w.stream {
+		// Created via NewReader -- the reader is one-shot, so
+		// keep its content for any later parse.
+		if w.src != nil {
+			data, err := readAll(w.src)
+			if err != nil {
+				return nil, err
 			}
-			return err
+			w.src, w.data = nil, data
 		}
-		defer f.Close() //nolint:errcheck // read-only
-		src = f
+
+		return w.data, nil
 	}
 
-	data, err := io.ReadAll(src)
+	// Created via New -- open the file fresh each time.
+	f, err := os.Open(w.filepath)
 	if err != nil {
-		return err
+		if w.optional && os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
 	}
+	defer f.Close() //nolint:errcheck // read-only
 
-	if closer, ok := src.(io.Closer); ok {
-		if err := closer.Close(); err != nil {
-			return err
-		}
+	return io.ReadAll(f)
+}
+
+// Close closes the src of NewReader if it hasn't been read yet.
+func (w *walker) Close() error {
+	src := w.src
+	w.src = nil
+	return closeSrc(src)
+}
+
+// readAll reads src to the end and closes it if it is an io.Closer.
+func readAll(src io.Reader) ([]byte, error) {
+	data, err := io.ReadAll(src)
+	if err != nil {
+		return nil, err
 	}
 
-	err = w.unmarshal(data, w.conf)
+	err = closeSrc(src)
 	if err != nil {
-		filePath := errors.New(w.filepath)
-		return errors.Join(filePath, err)
+		return nil, err
 	}
 
+	return data, nil
+}
+
+// closeSrc closes src if it is an io.Closer.
+func closeSrc(src io.Reader) error {
+	if closer, ok := src.(io.Closer); ok {
+		return closer.Close()
+	}
 	return nil
 }
@@ -217,9 +217,34 @@ func TestBadFileContent(t *testing.T) {
 		t.Errorf("expected error but got nil")
 	}
 
-	expect := "testdata/broken_json.json\ninvalid character 'i' looking for beginning of value"
+	expect := "testdata/broken_json.json: invalid character 'i' looking for beginning of value"
 	if err.Error() != expect {
 		fmt.Println(err)
 		t.Errorf("Unexpected error: %v", err)
 	}
 }
+
+type closeTracker struct {
+	io.Reader
+	closed int
+}
+
+func (c *closeTracker) Close() error {
+	c.closed++
+	return nil
+}
+
+func TestReaderClose(t *testing.T) {
+	src := &closeTracker{Reader: bytes.NewReader([]byte(`{}`))}
+
+	conf := uconfig.New[f.Config](file.NewReader(src, "config.json", json.Unmarshal))
+
+	err := conf.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if src.closed != 1 {
+		t.Errorf("expected the unread reader to be closed once, got %d", src.closed)
+	}
+}
@@ -0,0 +1,41 @@
+package file
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// documentKeys returns the paths of the keys of the document, down to
+// its values, or nil if it can not be unmarshaled into a map.
+func documentKeys(data []byte, unmarshal Unmarshal) [][]string {
+	var doc map[string]any
+	err := unmarshal(data, &doc)
+	if err != nil {
+		return nil
+	}
+
+	return appendKeys(nil, nil, reflect.ValueOf(doc))
+}
+
+// appendKeys appends the paths of the keys of value to keys, decoders
+// like yaml.v2 nest maps with keys of any type, so any map is walked.
+func appendKeys(keys [][]string, path []string, value reflect.Value) [][]string {
+	for value.Kind() == reflect.Interface && !value.IsNil() {
+		value = value.Elem()
+	}
+
+	if value.Kind() != reflect.Map || value.Len() == 0 {
+		if len(path) > 0 {
+			keys = append(keys, path)
+		}
+		return keys
+	}
+
+	iter := value.MapRange()
+	for iter.Next() {
+		key := fmt.Sprint(iter.Key().Interface())
+		keys = appendKeys(keys, append(path[:len(path):len(path)], key), iter.Value())
+	}
+
+	return keys
+}
@@ -6,6 +6,7 @@ import (
 	"os"
 	"path/filepath"
 
+	"github.com/omeid/uconfig/flat"
 	"github.com/omeid/uconfig/plugins"
 )
 
@@ -49,12 +50,27 @@ func NewMulti(path string, unmarshalOptions UnmarshalOptions, optional bool) plu
 type multiWalker struct {
 	filepath         string
 	src              io.Reader
+	data             []byte
 	conf             any
 	unmarshalOptions map[string]Unmarshal
+	keys             [][]string // keys of the last parse
 
 	err error
 }
 
+var (
+	_ plugins.Closer = (*multiWalker)(nil)
+	_ plugins.Keyer  = (*multiWalker)(nil)
+)
+
+func (v *multiWalker) Keys() [][]string {
+	return v.keys
+}
+
+func (v *multiWalker) Describe() (string, string) {
+	return "file", v.filepath
+}
+
 func (v *multiWalker) Walk(conf any) error {
 	if v.err != nil {
 		return v.err
@@ -69,20 +85,18 @@ func (v *multiWalker) Parse() error {
 		return v.err
 	}
 
-	if v.src == nil {
-		return nil
-	}
-
-	src, err := io.ReadAll(v.src)
-	if err != nil {
-		return err
-	}
-
-	if closer, ok := v.src.(io.Closer); ok {
-		err := closer.Close()
+	// the file is opened once, so keep its
+	// content for any later parse.
+	if v.src != nil {
+		data, err := readAll(v.src)
 		if err != nil {
 			return err
 		}
+		v.src, v.data = nil, data
+	}
+
+	if v.data == nil {
+		return nil
 	}
 
 	ext := filepath.Ext(v.filepath)
@@ -91,5 +105,19 @@ func (v *multiWalker) Parse() error {
 		return ErrFileExtNotSupported
 	}
 
-	return unmarshal(src, v.conf)
+	err := unmarshal(v.data, v.conf)
+	if err != nil {
+		return &flat.FieldError{Plugin: "file", Key: v.filepath, Err: err}
+	}
+
+	v.keys = documentKeys(v.data, unmarshal)
+
+	return nil
+}
+
+// Close closes the file if it hasn't been read yet.
+func (v *multiWalker) Close() error {
+	src := v.src
+	v.src = nil
+	return closeSrc(src)
 }
@@ -0,0 +1,371 @@
+package flag
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/omeid/uconfig/plugins"
+)
+
+const (
+	// completeArg is the hidden first argument that asks for the
+	// completion candidates of the last argument.
+	completeArg = "__complete"
+
+	// completionArg is the hidden first argument that asks for
+	// the completion script of a shell.
+	completionArg = "__completion"
+
+	// completeTag sets how the value of a flag is completed, it is
+	// either file, dir, or the values separated by |.
+	completeTag = "complete"
+
+	completeFile = "file"
+	completeDir  = "dir"
+)
+
+func init() {
+	plugins.RegisterTag(completeTag)
+}
+
+// Output is the io.Writer that completion candidates and scripts are
+// written to.
+var Output io.Writer = os.Stdout
+
+// candidate is a completion candidate and its description.
+type candidate struct {
+	value string
+	usage string
+}
+
+// complete writes the candidates for the last of the args, the args
+// before it are used to work out the selected command and whatever the
+// last argument is the value of a flag.
+//
+// The candidates are written one per line, followed by a tab and their
+// description if they have one. When the value is a path, a single
+// :file or :dir line is written instead for the shell to complete it.
+func (v *visitor) complete(args []string) error {
+	if len(args) == 0 {
+		args = []string{""}
+	}
+	words, current := args[:len(args)-1], args[len(args)-1]
+
+	scope, commands, pending := v.completeScope(words)
+
+	var (
+		candidates []candidate
+		directive  string
+	)
+
+	switch {
+	case pending != nil:
+		candidates, directive = valueCandidates(pending, "")
+
+	case strings.HasPrefix(current, "-") && strings.Contains(current, "="):
+		name, _, _ := strings.Cut(current, "=")
+		if def := v.lookup(strings.TrimLeft(name, "-"), scope); def != nil {
+			candidates, directive = valueCandidates(def, name+"=")
+		}
+
+	case strings.HasPrefix(current, "-"):
+		for _, def := range v.scopeFlags(scope) {
+			candidates = append(candidates, candidate{"-" + def.name, def.usage})
+		}
+
+	case len(v.commands) > 0:
+		for _, cmd := range commands {
+			candidates = append(candidates, candidate{cmd.Name, cmd.Usage})
+		}
+
+	case v.command != nil:
+		candidates, directive = fieldCandidates(v.command.Tag, "")
+	}
+
+	if directive != "" {
+		_, _ = fmt.Fprintf(Output, ":%s\n", directive)
+		return plugins.ErrCompletion
+	}
+
+	for _, c := range candidates {
+		if !strings.HasPrefix(c.value, current) {
+			continue
+		}
+
+		if c.usage == "" {
+			_, _ = fmt.Fprintln(Output, c.value)
+			continue
+		}
+		_, _ = fmt.Fprintf(Output, "%s\t%s\n", c.value, strings.ReplaceAll(c.usage, "\n", " "))
+	}
+
+	return plugins.ErrCompletion
+}
+
+// completeScope works out the selected command from the words and the
+// flag whose value is expected next, if any.
+func (v *visitor) completeScope(words []string) (string, []plugins.Command, *flagDef) {
+	var (
+		selected []string
+		pending  *flagDef
+	)
+
+	commands := v.commands
+
+	for _, word := range words {
+		if pending != nil {
+			pending = nil
+			continue
+		}
+
+		scope := strings.Join(selected, " ")
+
+		if strings.HasPrefix(word, "-") {
+			if strings.Contains(word, "=") {
+				continue
+			}
+
+			def := v.lookup(strings.TrimLeft(word, "-"), scope)
+			if def != nil && !def.flag.IsBoolFlag() {
+				pending = def
+			}
+			continue
+		}
+
+		if cmd := findCommand(commands, word); cmd != nil {
+			selected = append(selected, cmd.Name)
+			commands = cmd.Commands
+		}
+	}
+
+	return strings.Join(selected, " "), commands, pending
+}
+
+// scopeFlags returns the flags that can be used with the selected
+// command, in the order of the fields.
+func (v *visitor) scopeFlags(selected string) []*flagDef {
+	var defs []*flagDef
+	for i := range v.flags {
+		def := &v.flags[i]
+		if inScope(def.scope, selected) && v.lookup(def.name, selected) == def {
+			defs = append(defs, def)
+		}
+	}
+	return defs
+}
+
+// lookup returns the flag by name as it is used with the selected
+// command, where the flags of a command shadow its parents.
+func (v *visitor) lookup(name string, selected string) *flagDef {
+	var found *flagDef
+	for i := range v.flags {
+		def := &v.flags[i]
+		if def.name != name || !inScope(def.scope, selected) {
+			continue
+		}
+
+		if found == nil || len(def.scope) > len(found.scope) {
+			found = def
+		}
+	}
+	return found
+}
+
+// valueCandidates returns the values of the flag, prefixed by prefix.
+func valueCandidates(def *flagDef, prefix string) ([]candidate, string) {
+	if def.flag.IsBoolFlag() {
+		return []candidate{{value: prefix + "true"}, {value: prefix + "false"}}, ""
+	}
+
+	return fieldCandidates(def.flag.Tag, prefix)
+}
+
+// fieldCandidates returns the values of a field from its complete
+// tag, or the oneof rule of its validate tag.
+func fieldCandidates(tag func(string) (string, bool), prefix string) ([]candidate, string) {
+	var options []string
+
+	if complete, ok := tag(completeTag); ok {
+		switch complete {
+		case completeFile, completeDir:
+			return nil, complete
+		}
+		options = strings.Split(complete, "|")
+	} else if rules, ok := tag("validate"); ok {
+		options = oneOf(rules)
+	}
+
+	candidates := make([]candidate, 0, len(options))
+	for _, option := range options {
+		candidates = append(candidates, candidate{value: prefix + option})
+	}
+
+	return candidates, ""
+}
+
+// oneOf returns the options of the oneof validation rule.
+func oneOf(rules string) []string {
+	for rules != "" {
+		var rule string
+		// regexp is always the last rule and may contain commas.
+		if strings.HasPrefix(rules, "regexp=") {
+			return nil
+		}
+		rule, rules, _ = strings.Cut(rules, ",")
+
+		if options, ok := strings.CutPrefix(strings.TrimSpace(rule), "oneof="); ok {
+			return strings.Split(options, "|")
+		}
+	}
+
+	return nil
+}
+
+var scripts = map[string]string{
+	"bash":       bashScript,
+	"zsh":        zshScript,
+	"fish":       fishScript,
+	"powershell": powershellScript,
+}
+
+var nonIdent = regexp.MustCompile(`[^A-Za-z0-9_]`)
+
+// completion writes the completion script for the shell.
+func (v *visitor) completion(args []string) error {
+	var shell string
+	if len(args) > 0 {
+		shell = args[0]
+	}
+
+	script, ok := scripts[shell]
+	if !ok {
+		shells := make([]string, 0, len(scripts))
+		for name := range scripts {
+			shells = append(shells, name)
+		}
+		sort.Strings(shells)
+
+		return fmt.Errorf("unsupported shell %q, expecting one of: %s", shell, strings.Join(shells, ", "))
+	}
+
+	name := filepath.Base(v.fs.Name())
+
+	script = strings.NewReplacer(
+		"{{name}}", name,
+		"{{func}}", nonIdent.ReplaceAllString(name, "_"),
+	).Replace(script)
+
+	_, _ = io.WriteString(Output, script)
+
+	return plugins.ErrCompletion
+}
+
+const bashScript = `# bash completion for {{name}}, add to your ~/.bashrc:
+#   source <({{name}} __completion bash)
+_{{func}}_complete() {
+    local line="${COMP_LINE:0:COMP_POINT}"
+    local -a words
+    read -ra words <<< "$line"
+    [[ "$line" == *" " ]] && words+=("")
+
+    local cur="${words[${#words[@]}-1]}"
+    local out
+    out=$("${words[0]}" __complete "${words[@]:1}" 2>/dev/null) || true
+
+    local IFS=$'\n'
+    case "$out" in
+    :file) COMPREPLY=($(compgen -f -- "${cur#*=}")); return ;;
+    :dir) COMPREPLY=($(compgen -d -- "${cur#*=}")); return ;;
+    esac
+
+    COMPREPLY=()
+    local candidate
+    for candidate in $out; do
+        candidate="${candidate%%$'\t'*}"
+        # bash completes the part after = on its own.
+        if [[ "$cur" == *=* && "$COMP_WORDBREAKS" == *=* ]]; then
+            candidate="${candidate#*=}"
+        fi
+        COMPREPLY+=("$candidate")
+    done
+}
+complete -o nospace -o default -F _{{func}}_complete {{name}}
+`
+
+const zshScript = `#compdef {{name}}
+# zsh completion for {{name}}, add to your ~/.zshrc:
+#   source <({{name}} __completion zsh)
+_{{func}}() {
+    local out line value desc
+    local -a candidates
+    out=$("${words[1]}" __complete "${(@)words[2,CURRENT]}" 2>/dev/null)
+
+    for line in "${(@f)out}"; do
+        case "$line" in
+        :file) _files; return ;;
+        :dir) _files -/; return ;;
+        esac
+
+        value="${line%%$'\t'*}"
+        desc=""
+        [[ "$line" == *$'\t'* ]] && desc="${line#*$'\t'}"
+        candidates+=("${value//:/\\:}${desc:+:$desc}")
+    done
+
+    _describe '{{name}}' candidates
+}
+compdef _{{func}} {{name}}
+`
+
+const fishScript = `# fish completion for {{name}}, add to your config.fish:
+#   {{name}} __completion fish | source
+function __{{func}}_complete
+    set -l args (commandline -opc) (commandline -ct)
+    set -e args[1]
+    set -l current (string replace -r -- '^-[^=]*=' '' (commandline -ct))
+
+    for line in ({{name}} __complete $args 2>/dev/null)
+        switch $line
+            case ':file'
+                __fish_complete_path $current
+                return
+            case ':dir'
+                __fish_complete_directories $current
+                return
+            case '*'
+                echo $line
+        end
+    end
+end
+complete -c {{name}} -f -a '(__{{func}}_complete)'
+`
+
+const powershellScript = `# PowerShell completion for {{name}}, add to your $PROFILE:
+#   {{name}} __completion powershell | Out-String | Invoke-Expression
+Register-ArgumentCompleter -Native -CommandName '{{name}}' -ScriptBlock {
+    param($wordToComplete, $commandAst, $cursorPosition)
+
+    $words = @($commandAst.CommandElements | Select-Object -Skip 1 | ForEach-Object { $_.ToString() })
+    if ($wordToComplete -eq '') { $words += '""' }
+
+    $out = & '{{name}}' __complete @words 2>$null
+    foreach ($line in $out) {
+        if ($line -eq ':file' -or $line -eq ':dir') {
+            $path = $wordToComplete -replace '^-[^=]*=', ''
+            Get-ChildItem -Path "$path*" -Directory:($line -eq ':dir') | ForEach-Object {
+                [System.Management.Automation.CompletionResult]::new($_.Name, $_.Name, 'ProviderItem', $_.Name)
+            }
+            return
+        }
+
+        $value, $desc = $line -split "` + "`" + `t", 2
+        if (-not $desc) { $desc = $value }
+        [System.Management.Automation.CompletionResult]::new($value, $value, 'ParameterValue', $desc)
+    }
+}
+`
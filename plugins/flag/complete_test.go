@@ -0,0 +1,149 @@
+package flag_test
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/omeid/uconfig"
+	"github.com/omeid/uconfig/plugins"
+	"github.com/omeid/uconfig/plugins/flag"
+)
+
+type fComplete struct {
+	Command string `flag:",command" validate:"oneof=run|stop"`
+	Verbose bool   `usage:"log more"`
+	Mode    string `validate:"oneof=fast|slow"`
+	Config  string `complete:"file"`
+	Level   string `complete:"debug|info"`
+}
+
+func complete(t *testing.T, fs plugins.Plugin, conf func(plugins.Plugin) error) []string {
+	t.Helper()
+
+	var out bytes.Buffer
+	output := flag.Output
+	flag.Output = &out
+	defer func() { flag.Output = output }()
+
+	err := conf(fs)
+	if !errors.Is(err, plugins.ErrCompletion) {
+		t.Fatalf("expected ErrCompletion, got %v", err)
+	}
+
+	return strings.Split(strings.TrimSuffix(out.String(), "\n"), "\n")
+}
+
+func TestFlagComplete(t *testing.T) {
+	tests := []struct {
+		args   []string
+		expect []string
+	}{
+		{[]string{"-"}, []string{"-verbose\tlog more", "-mode", "-config", "-level"}},
+		{[]string{"-m"}, []string{"-mode"}},
+		{[]string{"-mode", ""}, []string{"fast", "slow"}},
+		{[]string{"-mode="}, []string{"-mode=fast", "-mode=slow"}},
+		{[]string{"-verbose="}, []string{"-verbose=true", "-verbose=false"}},
+		{[]string{"-level", "d"}, []string{"debug"}},
+		{[]string{"-config", ""}, []string{":file"}},
+		{[]string{"-verbose", "r"}, []string{"run"}},
+		{[]string{""}, []string{"run", "stop"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(strings.Join(tt.args, " "), func(t *testing.T) {
+			args := append([]string{"__complete"}, tt.args...)
+			fs := flag.New("testing", flag.ContinueOnError, args)
+
+			got := complete(t, fs, func(fs plugins.Plugin) error {
+				_, err := uconfig.New[fComplete](fs).Parse()
+				return err
+			})
+
+			if diff := cmp.Diff(tt.expect, got); diff != "" {
+				t.Error(diff)
+			}
+		})
+	}
+}
+
+func TestFlagCompleteCommands(t *testing.T) {
+	type Config struct {
+		Force bool
+		Cache struct {
+			Force bool `usage:"drop everything"`
+			Dir   string
+		}
+	}
+
+	tree := []plugins.Command{
+		{
+			Name:     "cache",
+			Usage:    "manage the cache",
+			Prefix:   "Cache",
+			Fields:   []string{"Cache.Force", "Cache.Dir"},
+			Commands: []plugins.Command{{Name: "clear", Usage: "clear the cache"}},
+		},
+		{Name: "serve"},
+	}
+
+	tests := []struct {
+		args   []string
+		expect []string
+	}{
+		{[]string{""}, []string{"cache\tmanage the cache", "serve"}},
+		{[]string{"-"}, []string{"-force"}},
+		{[]string{"cache", ""}, []string{"clear\tclear the cache"}},
+		{[]string{"cache", "-"}, []string{"-force\tdrop everything", "-dir"}},
+		{[]string{"cache", "-dir", "x", "clear", "-"}, []string{"-force\tdrop everything", "-dir"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(strings.Join(tt.args, " "), func(t *testing.T) {
+			args := append([]string{"__complete"}, tt.args...)
+			fs := flag.New("testing", flag.ContinueOnError, args)
+			fs.(plugins.Commander).SetCommands(tree)
+
+			got := complete(t, fs, func(fs plugins.Plugin) error {
+				_, err := uconfig.New[Config](fs).Parse()
+				return err
+			})
+
+			if diff := cmp.Diff(tt.expect, got); diff != "" {
+				t.Error(diff)
+			}
+		})
+	}
+}
+
+func TestFlagCompletion(t *testing.T) {
+	for _, shell := range []string{"bash", "zsh", "fish", "powershell"} {
+		t.Run(shell, func(t *testing.T) {
+			fs := flag.New("/usr/bin/my-app", flag.ContinueOnError, []string{"__completion", shell})
+
+			got := complete(t, fs, func(fs plugins.Plugin) error {
+				_, err := uconfig.New[fComplete](fs).Parse()
+				return err
+			})
+
+			script := strings.Join(got, "\n")
+			if !strings.Contains(script, "my-app") || !strings.Contains(script, "__complete ") {
+				t.Errorf("expected the script to call my-app __complete, got:\n%s", script)
+			}
+
+			if strings.Contains(script, "{{") || strings.Contains(script, "/usr/bin") {
+				t.Errorf("expected the script to be named after the program, got:\n%s", script)
+			}
+		})
+	}
+
+	fs := flag.New("testing", flag.ContinueOnError, []string{"__completion", "tcsh"})
+	_, err := uconfig.New[fComplete](fs).Parse()
+
+	expect := `unsupported shell "tcsh", expecting one of: bash, fish, powershell, zsh`
+	if err == nil || err.Error() != expect {
+		t.Errorf("expected (%s) but got (%v)", expect, err)
+	}
+}
@@ -8,6 +8,7 @@ import (
 	"os"
 	"reflect"
 	"slices"
+	"sort"
 	"strings"
 
 	"github.com/omeid/uconfig/flat"
@@ -18,6 +19,16 @@ import (
 const (
 	tag              = "flag"
 	commandFieldName = "[command]"
+
+	// printConfigFlag requests the effective config to be printed, once
+	// enabled by EnablePrintConfig, it takes an optional format, e.g.
+	// -print-config=json.
+	printConfigFlag   = "print-config"
+	printConfigFormat = "yaml"
+
+	// versionFlag requests the version to be printed,
+	// once enabled by SetVersion.
+	versionFlag = "version"
 )
 
 func init() {
@@ -34,33 +45,110 @@ const (
 	PanicOnError    = ErrorHandling(flag.PanicOnError)
 )
 
+// Option configures the flags.
+type Option func(*visitor)
+
+// WithNaming names the flags of the fields that have no explicit flag
+// tag, e.g. flat.KebabCase names Redis.MaxConns -redis-max-conns
+// instead of -redis-maxconns.
+func WithNaming(naming flat.Naming) Option {
+	return func(v *visitor) {
+		v.naming = naming
+	}
+}
+
 // New returns a new Flags
-func New(name string, errorHandling ErrorHandling, args []string) plugins.Plugin {
+func New(name string, errorHandling ErrorHandling, args []string, opts ...Option) plugins.Plugin {
 	fs := flag.NewFlagSet(name, flag.ErrorHandling(errorHandling))
 	fs.Usage = func() {}
 
-	return &visitor{
+	v := &visitor{
 		fs:          fs,
 		args:        args,
+		naming:      makeFlagName,
 		requiredSet: map[string]bool{},
 	}
+	for _, opt := range opts {
+		opt(v)
+	}
+	return v
 }
 
 // Standard returns a set of flags configured in the common way.
-// It is same as: `New(os.Args[0], ContinueOnError, os.Args[1:])`
-func Standard() plugins.Plugin {
-	return New(os.Args[0], ContinueOnError, os.Args[1:])
+// It is same as: `New(os.Args[0], ContinueOnError, os.Args[1:], opts...)`
+func Standard(opts ...Option) plugins.Plugin {
+	return New(os.Args[0], ContinueOnError, os.Args[1:], opts...)
 }
 
-var _ plugins.Visitor = (*visitor)(nil)
+// Namer is implemented by the flag plugin for extensions to
+// set the naming of plugins they have not created, like WithNaming.
+type Namer interface {
+	SetNaming(naming flat.Naming)
+}
+
+var (
+	_ Namer                 = (*visitor)(nil)
+	_ plugins.Visitor       = (*visitor)(nil)
+	_ plugins.Commander     = (*visitor)(nil)
+	_ plugins.Versioner     = (*visitor)(nil)
+	_ plugins.ConfigPrinter = (*visitor)(nil)
+)
 
 type visitor struct {
-	fs   *flag.FlagSet
-	args []string
+	fs     *flag.FlagSet
+	args   []string
+	naming flat.Naming
 
 	fields      []flat.Field
+	flags       []flagDef
 	command     flat.Field
 	requiredSet map[string]bool
+	setErr      error
+
+	printable   bool
+	printConfig printConfig
+
+	versioned bool
+	version   bool
+
+	commands []plugins.Command
+	selected []string
+}
+
+// flagDef is a flag of a field, scope is the path of the command
+// the field belongs to, joined by space, or empty for the root.
+type flagDef struct {
+	flag     *fieldFlag
+	name     string
+	usage    string
+	scope    string
+	required bool
+}
+
+// scopedField is a field that only applies to a command.
+type scopedField struct {
+	scope  string
+	prefix string
+}
+
+func (v *visitor) SetCommands(commands []plugins.Command) {
+	v.commands = commands
+}
+
+func (v *visitor) Command() []string {
+	return v.selected
+}
+
+func (v *visitor) SetVersion(string) {
+	v.versioned = true
+}
+
+func (v *visitor) SetNaming(naming flat.Naming) {
+	v.naming = naming
+}
+
+func (v *visitor) EnablePrintConfig() {
+	v.printable = true
 }
 
 func makeFlagName(name string) string {
@@ -71,6 +159,20 @@ func makeFlagName(name string) string {
 
 type fieldFlag struct {
 	flat.Field
+	name string
+
+	// the flag package doesn't wrap the errors returned by Set, so
+	// we hold on to it to return the *flat.FieldError instead.
+	err *error
+}
+
+// Set is used by standard library flag package.
+func (ff *fieldFlag) Set(value string) error {
+	err := ff.SetFrom(flat.Source{Plugin: tag, Key: "-" + ff.name, Value: value})
+	if err != nil {
+		*ff.err = err
+	}
+	return err
 }
 
 func (ff *fieldFlag) String() string {
@@ -85,6 +187,34 @@ func (f *fieldFlag) IsBoolFlag() bool {
 	return reflect.ValueOf(f.Field.Interface()).Kind() == reflect.Bool
 }
 
+// printConfig is the value of the print-config flag, it is the
+// requested format or empty if the flag was not provided.
+type printConfig string
+
+func (p *printConfig) Set(value string) error {
+	switch value {
+	case "true":
+		*p = printConfigFormat
+	case "false":
+		*p = ""
+	default:
+		*p = printConfig(value)
+	}
+	return nil
+}
+
+func (p *printConfig) String() string {
+	if p == nil {
+		return ""
+	}
+	return string(*p)
+}
+
+// Used by standard library flag package.
+func (p *printConfig) IsBoolFlag() bool {
+	return true
+}
+
 // Indicates whatever the field is "command" field.
 // Used by usage and maybe used for other plugins to exclude command.
 func IsCommand(f flat.Field) bool {
@@ -94,11 +224,13 @@ func IsCommand(f flat.Field) bool {
 func (v *visitor) Visit(fields flat.Fields) error {
 	v.fields = fields
 
-	// Reset FlagSet to allow re-visiting (e.g. config reload).
-	v.fs = flag.NewFlagSet(v.fs.Name(), v.fs.ErrorHandling())
-	v.fs.Usage = func() {}
+	// Reset to allow re-visiting (e.g. config reload).
+	v.flags = nil
 	v.requiredSet = map[string]bool{}
 	v.command = nil
+	v.selected = nil
+
+	scoped := scopedFields(v.commands, nil)
 
 	for _, f := range v.fields {
 
@@ -108,8 +240,15 @@ func (v *visitor) Visit(fields flat.Fields) error {
 			continue
 		}
 
+		fieldName, _ := f.Name("")
+		scope := scoped[fieldName]
+
 		if !explicit {
-			name = makeFlagName(name)
+			// command flags are named within their command.
+			if scope.prefix != "" {
+				name = strings.TrimPrefix(name, scope.prefix+".")
+			}
+			name = v.naming(name)
 		}
 
 		opts, _ := f.Tag(tag)
@@ -126,8 +265,16 @@ func (v *visitor) Visit(fields flat.Fields) error {
 			}
 		} else {
 			usage, _ := f.Tag("usage")
-			v.fs.Var(&fieldFlag{f}, name, usage)
-			if required {
+			v.flags = append(v.flags, flagDef{
+				flag:     &fieldFlag{f, name, &v.setErr},
+				name:     name,
+				usage:    usage,
+				scope:    scope.scope,
+				required: required,
+			})
+
+			// command flags are only required once selected.
+			if required && scope.scope == "" {
 				v.requiredSet[name] = false
 			}
 
@@ -136,9 +283,87 @@ func (v *visitor) Visit(fields flat.Fields) error {
 		}
 	}
 
+	v.printConfig = ""
+	v.version = false
+
+	fs, err := v.flagSet("")
+	if err != nil {
+		return err
+	}
+	v.fs = fs
+
 	return nil
 }
 
+// scopedFields maps the flat name of the fields that only
+// apply to a command to the command scope.
+func scopedFields(commands []plugins.Command, path []string) map[string]scopedField {
+	scoped := map[string]scopedField{}
+
+	for _, cmd := range commands {
+		path := append(path[:len(path):len(path)], cmd.Name)
+		for _, name := range cmd.Fields {
+			scoped[name] = scopedField{scope: strings.Join(path, " "), prefix: cmd.Prefix}
+		}
+
+		for name, field := range scopedFields(cmd.Commands, path) {
+			scoped[name] = field
+		}
+	}
+
+	return scoped
+}
+
+// inScope reports whether a flag of the given scope can be used with
+// the selected command scope, flags of parent commands can be used
+// with their subcommands.
+func inScope(scope string, selected string) bool {
+	return scope == "" || scope == selected || strings.HasPrefix(selected, scope+" ")
+}
+
+// flagSet returns the flags that can be used with the selected command,
+// the flags of a command shadow those of its parents with the same name.
+func (v *visitor) flagSet(selected string) (*flag.FlagSet, error) {
+	fs := flag.NewFlagSet(v.fs.Name(), v.fs.ErrorHandling())
+	fs.Usage = func() {}
+
+	var defs []flagDef
+	for _, def := range v.flags {
+		if inScope(def.scope, selected) {
+			defs = append(defs, def)
+		}
+	}
+
+	// deepest command first.
+	sort.SliceStable(defs, func(i, j int) bool {
+		return len(defs[i].scope) > len(defs[j].scope)
+	})
+
+	scopes := map[string]string{}
+	for _, def := range defs {
+		if scope, ok := scopes[def.name]; ok {
+			if scope == def.scope {
+				return nil, fmt.Errorf("flag -%s is defined more than once", def.name)
+			}
+			continue
+		}
+
+		scopes[def.name] = def.scope
+		fs.Var(def.flag, def.name, def.usage)
+	}
+
+	// fields take precedence over print-config and version.
+	if v.printable && fs.Lookup(printConfigFlag) == nil {
+		fs.Var(&v.printConfig, printConfigFlag, "print the config and exit")
+	}
+
+	if v.versioned && fs.Lookup(versionFlag) == nil {
+		fs.BoolVar(&v.version, versionFlag, false, "print the version and exit")
+	}
+
+	return fs, nil
+}
+
 func extractCommand(args []string, fields flat.Fields) (string, []string, bool) {
 	lastIndex := len(args) - 1
 
@@ -182,6 +407,19 @@ func extractCommand(args []string, fields flat.Fields) (string, []string, bool)
 }
 
 func (v *visitor) Parse() error {
+	if len(v.args) > 0 {
+		switch v.args[0] {
+		case completeArg:
+			return v.complete(v.args[1:])
+		case completionArg:
+			return v.completion(v.args[1:])
+		}
+	}
+
+	if len(v.commands) > 0 {
+		return v.parseCommands()
+	}
+
 	args := v.args
 
 	if v.command != nil {
@@ -191,7 +429,7 @@ func (v *visitor) Parse() error {
 		command, args, found = extractCommand(args, v.fields)
 
 		if found {
-			err := v.command.Set(command)
+			err := v.command.SetFrom(flat.Source{Plugin: tag, Key: commandFieldName, Value: command})
 			if err != nil {
 				return err
 			}
@@ -205,26 +443,149 @@ func (v *visitor) Parse() error {
 		return fmt.Errorf("bad argument at the start: (%s)", args[0])
 	}
 
-	err := v.fs.Parse(args)
+	err := v.parseFlags(v.fs, args)
+	if err != nil {
+		return err
+	}
+
+	extraneous := v.fs.Args()
+
+	if len(extraneous) != 0 {
+		return fmt.Errorf("extra arguments provided: (%s)", strings.Join(extraneous, ","))
+	}
+
+	return v.missing()
+}
+
+// parseCommands parses the args level by level, the flags before the
+// first command, then the command name followed by its flags and so on.
+func (v *visitor) parseCommands() error {
+	args := v.args
+	fs := v.fs
+	commands := v.commands
+
+	for {
+		err := v.parseFlags(fs, args)
+		if err != nil {
+			return err
+		}
+
+		args = fs.Args()
+		if len(args) == 0 {
+			break
+		}
+
+		cmd := findCommand(commands, args[0])
+		if cmd == nil {
+			if len(commands) == 0 {
+				return fmt.Errorf("extra arguments provided: (%s)", strings.Join(args, ","))
+			}
+			return fmt.Errorf("unknown command %q, expecting one of: %s", args[0], commandNames(commands))
+		}
+
+		v.selected = append(v.selected, cmd.Name)
+		args, commands = args[1:], cmd.Commands
+
+		fs, err = v.flagSet(strings.Join(v.selected, " "))
+		if err != nil {
+			return err
+		}
+	}
+
+	if v.command != nil && len(v.selected) > 0 {
+		err := v.setCommand()
+		if err != nil {
+			return err
+		}
+	}
+
+	// flags of commands that were not selected are not required.
+	selected := strings.Join(v.selected, " ")
+	for _, def := range v.flags {
+		if !def.required || !inScope(def.scope, selected) {
+			continue
+		}
+
+		if _, ok := v.requiredSet[def.name]; !ok {
+			v.requiredSet[def.name] = false
+		}
+	}
+
+	return v.missing()
+}
+
+// parseFlags parses the flags at the start of args.
+func (v *visitor) parseFlags(fs *flag.FlagSet, args []string) error {
+	v.setErr = nil
+	err := fs.Parse(args)
 
 	if errors.Is(err, flag.ErrHelp) {
 		return plugins.ErrUsage
 	}
 
+	if err != nil && v.setErr != nil {
+		return v.setErr
+	}
+
 	if err != nil {
 		return err
 	}
 
-	extraneous := v.fs.Args()
+	if v.version {
+		return plugins.ErrVersion
+	}
 
-	if len(extraneous) != 0 {
-		return fmt.Errorf("extra arguments provided: (%s)", strings.Join(extraneous, ","))
+	// the flags are set so that they are part of the printed
+	// config, but missing ones shouldn't stop it from printing.
+	if v.printConfig != "" && len(fs.Args()) == 0 {
+		return &plugins.PrintConfig{Format: string(v.printConfig)}
 	}
 
-	v.fs.Visit(func(f *flag.Flag) {
+	fs.Visit(func(f *flag.Flag) {
 		v.requiredSet[f.Name] = true
 	})
 
+	return nil
+}
+
+// setCommand sets the command field to the selected command, a
+// slice field gets the names, otherwise they are joined by space.
+func (v *visitor) setCommand() error {
+	sep := " "
+	if reflect.ValueOf(v.command.Interface()).Kind() == reflect.Slice {
+		sep = ","
+	}
+
+	err := v.command.SetFrom(flat.Source{Plugin: tag, Key: commandFieldName, Value: strings.Join(v.selected, sep)})
+	if err != nil {
+		return err
+	}
+
+	v.requiredSet[commandFieldName] = true
+	return nil
+}
+
+func findCommand(commands []plugins.Command, name string) *plugins.Command {
+	for i := range commands {
+		if commands[i].Name == name {
+			return &commands[i]
+		}
+	}
+	return nil
+}
+
+func commandNames(commands []plugins.Command) string {
+	names := make([]string, len(commands))
+	for i, cmd := range commands {
+		names[i] = cmd.Name
+	}
+	return strings.Join(names, ", ")
+}
+
+// missing reports the required flags that were not provided.
+func (v *visitor) missing() error {
+	var err error
+
 	// get stable error messages.
 	fields := maps.Keys(v.requiredSet)
 	slices.Sort(fields)
@@ -235,9 +596,5 @@ func (v *visitor) Parse() error {
 		}
 	}
 
-	if err != nil {
-		return err
-	}
-
-	return nil
+	return err
 }
@@ -5,7 +5,9 @@ import (
 
 	"github.com/google/go-cmp/cmp"
 	"github.com/omeid/uconfig"
+	"github.com/omeid/uconfig/flat"
 	"github.com/omeid/uconfig/internal/f"
+	"github.com/omeid/uconfig/plugins"
 	"github.com/omeid/uconfig/plugins/defaults"
 	"github.com/omeid/uconfig/plugins/flag"
 )
@@ -338,3 +340,81 @@ func TestFlagExtraArgs(t *testing.T) {
 		t.Errorf("expected (%s) but got (%s)", expect, err)
 	}
 }
+
+func TestFlagPrintConfigField(t *testing.T) {
+	type Config struct {
+		PrintConfig bool `flag:"print-config"`
+	}
+
+	fs := flag.New("testing", flag.ContinueOnError, []string{"-print-config"})
+	fs.(plugins.ConfigPrinter).EnablePrintConfig()
+
+	value, err := uconfig.New[Config](fs).Parse()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !value.PrintConfig {
+		t.Error("expected the field to take precedence over the print-config flag")
+	}
+}
+
+func TestFlagCommands(t *testing.T) {
+	type Config struct {
+		Command string `flag:",command"`
+		Force   bool
+		Cache   struct {
+			Force bool
+		}
+	}
+
+	fs := flag.New("testing", flag.ContinueOnError, []string{"cache", "clear", "-force"})
+	fs.(plugins.Commander).SetCommands([]plugins.Command{
+		{
+			Name:     "cache",
+			Prefix:   "Cache",
+			Fields:   []string{"Cache.Force"},
+			Commands: []plugins.Command{{Name: "clear"}},
+		},
+	})
+
+	value, err := uconfig.New[Config](fs).Parse()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if value.Command != "cache clear" {
+		t.Errorf("expected command to be %q, got %q", "cache clear", value.Command)
+	}
+
+	// the command flag shadows the root one of the same name.
+	if value.Force || !value.Cache.Force {
+		t.Errorf("expected -force to set Cache.Force only, got %+v", value)
+	}
+}
+
+func TestFlagNaming(t *testing.T) {
+	type Config struct {
+		MaxConns   int
+		HTTPServer struct {
+			ReadTimeout string
+		}
+		Address string `flag:"host"`
+	}
+
+	args := []string{"-max-conns=10", "-http-server-read-timeout=5s", "-host=localhost"}
+
+	expect := &Config{MaxConns: 10, Address: "localhost"}
+	expect.HTTPServer.ReadTimeout = "5s"
+
+	fs := flag.New("testing", flag.ContinueOnError, args, flag.WithNaming(flat.KebabCase))
+
+	value, err := uconfig.New[Config](fs).Parse()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if diff := cmp.Diff(expect, value); diff != "" {
+		t.Error(diff)
+	}
+}
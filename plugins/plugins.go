@@ -16,6 +16,15 @@ type Plugin interface {
 	Parse() error
 }
 
+// ContextPlugin is an optional interface for plugins that can be
+// cancelled or given a deadline, like those talking to remote services.
+// When implemented, ParseContext is called instead of Parse.
+type ContextPlugin interface {
+	Plugin
+
+	ParseContext(ctx context.Context) error
+}
+
 // Walker is the interface for providers that take the whole
 // config, like file loaders.
 type Walker interface {
@@ -35,7 +44,10 @@ type Visitor interface {
 // Extension is the interface for plugins that need access to the full
 // plugin list. Like all plugins, Extensions are set up in registration
 // order — place them after any plugins they need to inspect (e.g. after
-// file plugins so that paths are resolved).
+// file plugins so that paths are resolved). The extensions of uconfig
+// that set up other plugins, like uconfig.EnvPrefix, are the exception,
+// they are set up before all the other plugins wherever they are
+// registered.
 type Extension interface {
 	Plugin
 
@@ -52,12 +64,110 @@ type Updater interface {
 	Updated(ctx context.Context) bool
 }
 
+// Command describes a command of a command tree and the fields
+// of the config that only apply to it.
+type Command struct {
+	Name  string
+	Usage string
+
+	// Prefix is the flat name prefix of the command fields, e.g. DB.Migrate,
+	// it is empty when the command config is embedded in its parent.
+	Prefix string
+
+	// Fields are the flat names of the fields that only apply to
+	// the command, excluding those of its subcommands.
+	Fields []string
+
+	Commands []Command
+}
+
+// Commander is an optional interface for plugins that select a
+// command from a command tree, mostly flags.
+type Commander interface {
+	// SetCommands sets the command tree, it is called before Visit.
+	SetCommands(commands []Command)
+
+	// Command returns the names of the selected command and its
+	// parents after Parse, e.g. [db migrate up], or nil if none.
+	Command() []string
+}
+
+// Versioner is an optional interface for plugins that can request
+// the version of the program to be printed, mostly flags, by
+// returning ErrVersion from Parse.
+type Versioner interface {
+	// SetVersion enables the request, it is called before Visit.
+	SetVersion(version string)
+}
+
+// ConfigPrinter is an optional interface for plugins that can request
+// the effective config to be printed, mostly flags, by returning a
+// *PrintConfig from Parse.
+type ConfigPrinter interface {
+	// EnablePrintConfig enables the request, it is called before Visit.
+	EnablePrintConfig()
+}
+
+// Closer is an optional interface for plugins that hold resources,
+// like open files, connections, or goroutines. Close is called by
+// Config.Close and once Watch returns, after which the plugin is not
+// used again.
+type Closer interface {
+	Close() error
+}
+
+// Describer is an optional interface for plugins to describe their
+// source, e.g. a file walker returns its path. It is used to record
+// the provenance of values set by Walkers, as those set the config
+// as a whole instead of field by field.
+type Describer interface {
+	// Describe returns the kind of the plugin and the key of its source.
+	Describe() (kind string, key string)
+}
+
+// Keyer is an optional interface for Walkers to list the keys their
+// source holds, as the paths of nested keys, e.g. [redis port]. The
+// fields found under them are recorded as set by the walker even when
+// the value has not changed, e.g. set to its default or zero value.
+// Without it, only the fields a walker changes are recorded.
+type Keyer interface {
+	// Keys returns the keys of the last parse.
+	Keys() [][]string
+}
+
 var tags = map[string]string{}
 
 // ErrUsage is returned when the user has requested a usage message
 // via some plugin, mostly flags.
 var ErrUsage = errors.New("uconfig: usage request")
 
+// ErrPrintConfig is returned when the user has requested the effective
+// config to be printed via some plugin, mostly flags.
+var ErrPrintConfig = errors.New("uconfig: print config request")
+
+// ErrVersion is returned when the user has requested the version
+// of the program via some plugin, mostly flags.
+var ErrVersion = errors.New("uconfig: version request")
+
+// ErrCompletion is returned when a plugin has written the shell
+// completion candidates or script the user has requested, mostly flags.
+var ErrCompletion = errors.New("uconfig: completion request")
+
+// PrintConfig is the error plugins return to request the effective
+// config to be printed in Format, it matches ErrPrintConfig.
+type PrintConfig struct {
+	Format string
+}
+
+func (p *PrintConfig) Error() string {
+	return ErrPrintConfig.Error()
+}
+
+// Is makes errors.Is(err, ErrPrintConfig) true.
+func (p *PrintConfig) Is(target error) bool {
+	return target == ErrPrintConfig
+}
+
 // RegisterTag allows providers to ensure their tag is unique.
 // they must call this function from an init.
 func RegisterTag(name string) {
@@ -2,6 +2,7 @@
 package secret
 
 import (
+	"context"
 	"errors"
 	"strings"
 
@@ -18,16 +19,48 @@ func init() {
 // Sourcer is any function that can exchange a secret name for its value.
 type Sourcer func(string) (string, error)
 
+// SourcerContext is like Sourcer, but can be cancelled or given a
+// deadline, e.g. for secrets that are fetched from a remote service.
+type SourcerContext func(ctx context.Context, name string) (string, error)
+
+// Option configures the secret provider.
+type Option func(*secret)
+
+// WithNaming names the secrets of the fields that have no explicit
+// name in their secret tag, e.g. flat.KebabCase asks for the secret
+// of DB.Password as db-password instead of DB_PASSWORD.
+func WithNaming(naming flat.Naming) Option {
+	return func(s *secret) {
+		s.naming = naming
+	}
+}
+
 // New returns the secret provider.
-func New(source Sourcer) plugins.Plugin {
-	return &secret{source: source}
+func New(source Sourcer, opts ...Option) plugins.Plugin {
+	return NewContext(func(_ context.Context, name string) (string, error) {
+		return source(name)
+	}, opts...)
+}
+
+// NewContext returns the secret provider for a SourcerContext, it is
+// given the context of Config.ParseContext, or context.Background()
+// when parsed with Parse.
+func NewContext(source SourcerContext, opts ...Option) plugins.Plugin {
+	s := &secret{source: source, naming: makeSecretName}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
 }
 
 var ErrSecretNotFound = errors.New("secret not found")
 
+var _ plugins.ContextPlugin = (*secret)(nil)
+
 type secret struct {
 	fields flat.Fields
-	source Sourcer
+	source SourcerContext
+	naming flat.Naming
 }
 
 func makeSecretName(name string) string {
@@ -49,7 +82,7 @@ func (v *secret) Visit(f flat.Fields) error {
 
 		name, explicit := f.Name(tag)
 		if !explicit {
-			name = makeSecretName(name)
+			name = v.naming(name)
 		}
 
 		f.Meta()[tag] = name
@@ -59,26 +92,37 @@ func (v *secret) Visit(f flat.Fields) error {
 }
 
 func (v *secret) Parse() error {
+	return v.ParseContext(context.Background())
+}
+
+func (v *secret) ParseContext(ctx context.Context) error {
+	var errs error
+
 	for _, f := range v.fields {
 		name := f.Meta()[tag]
 		if name == "" || name == "-" {
 			continue
 		}
 
-		value, err := v.source(name)
+		// don't report every remaining secret as failed.
+		if err := ctx.Err(); err != nil {
+			return errors.Join(errs, err)
+		}
+
+		value, err := v.source(ctx, name)
 		if err != nil {
-			return err
+			field, _ := f.Name("")
+			errs = errors.Join(errs, &flat.FieldError{Field: field, Plugin: tag, Key: name, Err: err})
+			continue
 		}
 
 		if value == "" {
 			continue
 		}
 
-		err = f.Set(value)
-		if err != nil {
-			return err
-		}
+		err = f.SetFrom(flat.Source{Plugin: tag, Key: name, Value: value})
+		errs = errors.Join(errs, err)
 	}
 
-	return nil
+	return errs
 }
@@ -1,11 +1,15 @@
 package secret_test
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"testing"
+	"time"
 
 	"github.com/google/go-cmp/cmp"
 	"github.com/omeid/uconfig"
+	"github.com/omeid/uconfig/flat"
 	"github.com/omeid/uconfig/plugins/secret"
 )
 
@@ -77,7 +81,11 @@ func TestSecretErr(t *testing.T) {
 		return
 	}
 
-	expect := "No value found for: PASSWORD"
+	expect := "Password (PASSWORD): No value found for: PASSWORD\n" +
+		"EmptyValue (EMPTYVALUE): No value found for: EMPTYVALUE\n" +
+		"Alt (AltPassword): No value found for: AltPassword\n" +
+		"Nested.Pass (NESTED_PASS): No value found for: NESTED_PASS\n" +
+		"Nested.Named (NAME_KEY): No value found for: NAME_KEY"
 	if err.Error() != expect {
 		t.Fatalf("Expected: %s\nGot: %s", expect, err)
 	}
@@ -101,8 +109,66 @@ func TestSecretSetErr(t *testing.T) {
 		return
 	}
 
-	expect := "strconv.ParseInt: parsing \"not a number\": invalid syntax"
+	expect := "Count (COUNT): strconv.ParseInt: parsing \"not a number\": invalid syntax"
 	if err.Error() != expect {
 		t.Fatalf("Expected: %s\nGot: %s", expect, err)
 	}
 }
+
+func TestSecretContext(t *testing.T) {
+	type Creds struct {
+		Token string `secret:""`
+		Key   string `secret:""`
+	}
+
+	var calls int
+	source := func(ctx context.Context, name string) (string, error) {
+		calls++
+		<-ctx.Done()
+		return "", ctx.Err()
+	}
+
+	conf := uconfig.New[Creds](secret.NewContext(source))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	_, err := conf.ParseContext(ctx)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected context.DeadlineExceeded, got %v", err)
+	}
+
+	if calls != 1 {
+		t.Errorf("expected the source to be called once, got %d", calls)
+	}
+}
+
+func TestSecretNaming(t *testing.T) {
+	type Config struct {
+		DB struct {
+			UserPassword string `secret:""`
+			APIKey       string `secret:"api_key"`
+		}
+	}
+
+	var names []string
+	source := func(name string) (string, error) {
+		names = append(names, name)
+		return name + "-value", nil
+	}
+
+	conf := uconfig.New[Config](secret.New(source, secret.WithNaming(flat.KebabCase)))
+
+	value, err := conf.Parse()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if diff := cmp.Diff([]string{"db-user-password", "api_key"}, names); diff != "" {
+		t.Error(diff)
+	}
+
+	if value.DB.UserPassword != "db-user-password-value" {
+		t.Errorf("expected the secret by its kebab case name, got %q", value.DB.UserPassword)
+	}
+}
@@ -0,0 +1,222 @@
+package uconfig
+
+import (
+	"fmt"
+	"io"
+	"reflect"
+	"slices"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/omeid/uconfig/flat"
+	"github.com/omeid/uconfig/plugins"
+)
+
+const (
+	secretTag = "secret"
+	masked    = "******"
+)
+
+func (c *config[C]) Sources() map[string][]flat.Source {
+	fields := c.current()
+	sources := make(map[string][]flat.Source, len(fields))
+
+	for _, f := range fields {
+		name, _ := f.Name("")
+		sources[name] = f.Sources()
+	}
+
+	return sources
+}
+
+func (c *config[C]) Explain(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 4, ' ', 0)
+	_, _ = fmt.Fprintln(tw, "FIELD\tPLUGIN\tKEY\tVALUE")
+	_, _ = fmt.Fprintln(tw, "-----\t------\t---\t-----")
+
+	for _, f := range c.current() {
+		name, _ := f.Name("")
+
+		sources := f.Sources()
+		if len(sources) == 0 {
+			_, _ = fmt.Fprintf(tw, "%s\t-\t-\t%s\n", name, displayValue(f, formatValue(f.Interface())))
+			continue
+		}
+
+		for i, src := range sources {
+			if i > 0 {
+				name = ""
+			}
+
+			key := src.Key
+			if key == "" {
+				key = "-"
+			}
+
+			_, _ = fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", name, src.Plugin, key, displayValue(f, src.Value))
+		}
+	}
+
+	return tw.Flush()
+}
+
+// displayValue masks the value of secret and sensitive fields.
+func displayValue(f flat.Field, value string) string {
+	if isSensitive(f) && value != "" {
+		return masked
+	}
+	return value
+}
+
+// formatValue formats a field value the way plugins would provide it.
+func formatValue(value any) string {
+	rv := reflect.ValueOf(value)
+
+	for rv.Kind() == reflect.Pointer {
+		if rv.IsNil() {
+			return ""
+		}
+		rv = rv.Elem()
+	}
+
+	if !rv.IsValid() {
+		return ""
+	}
+
+	switch rv.Kind() {
+	case reflect.Slice:
+		values := make([]string, rv.Len())
+		for i := range values {
+			values[i] = formatValue(rv.Index(i).Interface())
+		}
+		return strings.Join(values, ",")
+	case reflect.Map:
+		keys := rv.MapKeys()
+		values := make([]string, 0, len(keys))
+		for _, k := range keys {
+			values = append(values, formatValue(k.Interface())+":"+formatValue(rv.MapIndex(k).Interface()))
+		}
+		slices.Sort(values)
+		return strings.Join(values, ",")
+	}
+
+	return fmt.Sprint(rv.Interface())
+}
+
+func isWalker(p plugins.Plugin) bool {
+	if _, ok := p.(plugins.Visitor); ok {
+		return false
+	}
+
+	_, ok := p.(plugins.Walker)
+	return ok
+}
+
+// snapshot takes a deep copy of the fields values so that in-place
+// changes, like a decoder reusing a slice, are not missed.
+func snapshot(fs flat.Fields) []any {
+	values := make([]any, len(fs))
+	for i, f := range fs {
+		values[i] = deepCopy(reflect.ValueOf(f.Interface())).Interface()
+	}
+	return values
+}
+
+// record adds the plugin to the history of every field it changed,
+// or that is under one of its keys.
+func record(p plugins.Plugin, fs flat.Fields, before []any) {
+	kind, key := "walker", ""
+	if d, ok := p.(plugins.Describer); ok {
+		kind, key = d.Describe()
+	}
+
+	var keys [][]string
+	if k, ok := p.(plugins.Keyer); ok {
+		keys = k.Keys()
+	}
+
+	for i, f := range fs {
+		value := f.Interface()
+		if reflect.DeepEqual(before[i], value) && !hasKey(f, keys) {
+			continue
+		}
+
+		f.Record(flat.Source{Plugin: kind, Key: key, Value: formatValue(value)})
+	}
+}
+
+// fileTags are the struct tags that file unmarshalers name fields with.
+var fileTags = []string{"json", "yaml", "toml"}
+
+// hasKey reports whether the field is under one of the keys, as named
+// by any of the file unmarshalers. The keys are matched regardless of
+// case, like encoding/json does.
+func hasKey(f flat.Field, keys [][]string) bool {
+	for _, tag := range fileTags {
+		path := f.Path(tag)
+		if path == nil {
+			continue
+		}
+
+		for _, key := range keys {
+			if hasPrefix(key, path) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+func hasPrefix(key []string, path []string) bool {
+	if len(key) < len(path) {
+		return false
+	}
+
+	for i, name := range path {
+		if !strings.EqualFold(key[i], name) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func deepCopy(v reflect.Value) reflect.Value {
+	if !v.IsValid() {
+		return v
+	}
+
+	switch v.Kind() {
+	case reflect.Pointer:
+		if v.IsNil() {
+			return v
+		}
+		c := reflect.New(v.Type().Elem())
+		c.Elem().Set(deepCopy(v.Elem()))
+		return c
+
+	case reflect.Slice:
+		if v.IsNil() {
+			return v
+		}
+		c := reflect.MakeSlice(v.Type(), v.Len(), v.Len())
+		for i := 0; i < v.Len(); i++ {
+			c.Index(i).Set(deepCopy(v.Index(i)))
+		}
+		return c
+
+	case reflect.Map:
+		if v.IsNil() {
+			return v
+		}
+		c := reflect.MakeMapWithSize(v.Type(), v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			c.SetMapIndex(iter.Key(), deepCopy(iter.Value()))
+		}
+		return c
+	}
+
+	return v
+}
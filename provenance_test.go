@@ -0,0 +1,135 @@
+package uconfig_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/omeid/uconfig"
+	"github.com/omeid/uconfig/flat"
+	"github.com/omeid/uconfig/internal/f"
+	"github.com/omeid/uconfig/plugins/defaults"
+	"github.com/omeid/uconfig/plugins/env"
+	"github.com/omeid/uconfig/plugins/file"
+	"github.com/omeid/uconfig/plugins/flag"
+	"github.com/omeid/uconfig/plugins/secret"
+)
+
+func TestSources(t *testing.T) {
+	t.Setenv("REDIS_PORT", "6380")
+	t.Setenv("REDIS_ADDRESS", "")
+	os.Unsetenv("REDIS_ADDRESS")
+
+	srcJSON := `{"Redis": {"Host": "redis-host", "Port": 6379}}`
+
+	conf := uconfig.New[f.Config](
+		defaults.New(),
+		file.NewReader(bytes.NewReader([]byte(srcJSON)), "config.json", json.Unmarshal),
+		env.New(),
+		flag.New("testing", flag.ContinueOnError, []string{"-redis-address=from-flag"}),
+	)
+
+	_, err := conf.Parse()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sources := conf.Sources()
+
+	expect := map[string][]flat.Source{
+		"Redis.Address": {
+			{Plugin: "file", Key: "config.json", Value: "redis-host"},
+			{Plugin: "flag", Key: "-redis-address", Value: "from-flag"},
+		},
+		"Redis.Port": {
+			{Plugin: "file", Key: "config.json", Value: "6379"},
+			{Plugin: "env", Key: "REDIS_PORT", Value: "6380"},
+		},
+		"Rethink.Db": {
+			{Plugin: "default", Value: "primary"},
+		},
+		"Command": {
+			{Plugin: "default", Value: "run"},
+		},
+	}
+
+	for name, want := range expect {
+		if diff := cmp.Diff(want, sources[name]); diff != "" {
+			t.Errorf("%s: %s", name, diff)
+		}
+	}
+
+	if got := sources["GoHard"]; len(got) != 0 {
+		t.Errorf("expected no sources for GoHard, got %v", got)
+	}
+}
+
+func TestSourcesUnchanged(t *testing.T) {
+	type Config struct {
+		Port    int  `default:"6379"`
+		Enabled bool `json:"enabled"`
+		Debug   bool
+	}
+
+	// the values are the same as before the file.
+	srcJSON := `{"port": 6379, "enabled": false}`
+
+	conf := uconfig.New[Config](
+		defaults.New(),
+		file.NewReader(bytes.NewReader([]byte(srcJSON)), "config.json", json.Unmarshal),
+	)
+
+	_, err := conf.Parse()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expect := map[string][]flat.Source{
+		"Port": {
+			{Plugin: "default", Value: "6379"},
+			{Plugin: "file", Key: "config.json", Value: "6379"},
+		},
+		"Enabled": {
+			{Plugin: "file", Key: "config.json", Value: "false"},
+		},
+		"Debug": nil,
+	}
+
+	if diff := cmp.Diff(expect, conf.Sources()); diff != "" {
+		t.Error(diff)
+	}
+}
+
+func TestExplain(t *testing.T) {
+	type Config struct {
+		Password string `default:"changeme" secret:""`
+		hidden   string
+	}
+
+	source := func(string) (string, error) { return "top secret token", nil }
+
+	conf := uconfig.New[Config](defaults.New(), secret.New(source))
+	_, err := conf.Parse()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var out bytes.Buffer
+	err = conf.Explain(&out)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expect := `FIELD       PLUGIN     KEY         VALUE
+-----       ------     ---         -----
+Password    default    -           ******
+            secret     PASSWORD    ******
+hidden      -          -           
+`
+
+	if diff := cmp.Diff(expect, out.String()); diff != "" {
+		t.Error(diff)
+	}
+}
@@ -0,0 +1,89 @@
+package uconfig
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/omeid/uconfig/flat"
+	"github.com/omeid/uconfig/plugins"
+	"github.com/omeid/uconfig/plugins/file"
+)
+
+const requiredTag = "required"
+
+func init() {
+	plugins.RegisterTag(requiredTag)
+}
+
+// required reports every field tagged with required that no plugin
+// has set, along with all the ways it could have been set.
+func required(fs flat.Fields, ps []plugins.Plugin, failed map[string]bool) error {
+	hasFiles := len(file.FileNames(ps)) > 0
+
+	var errs error
+
+	for _, f := range fs {
+		if _, ok := f.Tag(requiredTag); !ok {
+			continue
+		}
+
+		if len(f.Sources()) > 0 {
+			continue
+		}
+
+		name, _ := f.Name("")
+		if failed[name] {
+			continue
+		}
+
+		errs = errors.Join(errs, &FieldError{
+			Field:  name,
+			Plugin: requiredTag,
+			Key:    strings.Join(supplyWays(f, hasFiles), ", "),
+			Err:    ErrRequired,
+		})
+	}
+
+	return errs
+}
+
+// supplyWays lists the sources a field can be set from.
+func supplyWays(f flat.Field, hasFiles bool) []string {
+	var ways []string
+
+	for _, key := range []string{"flag", "env", "env_file", secretTag} {
+		name := f.Meta()[key]
+		if name == "" || name == "-" {
+			continue
+		}
+		ways = append(ways, key+" "+name)
+	}
+
+	if keys := fileKeys(f); hasFiles && len(keys) > 0 {
+		ways = append(ways, "file key "+strings.Join(keys, " or "))
+	}
+
+	return ways
+}
+
+// fileKeys returns the keys of the field as the JSON and YAML
+// unmarshalers see them, and TOML unless it only differs from JSON
+// by case, which neither of them minds.
+func fileKeys(f flat.Field) []string {
+	var keys []string
+
+	json := strings.Join(f.Path("json"), ".")
+	if json != "" {
+		keys = append(keys, json)
+	}
+
+	if yaml := strings.Join(f.Path("yaml"), "."); yaml != "" && yaml != json {
+		keys = append(keys, yaml)
+	}
+
+	if toml := strings.Join(f.Path("toml"), "."); toml != "" && !strings.EqualFold(toml, json) {
+		keys = append(keys, toml)
+	}
+
+	return keys
+}
@@ -0,0 +1,125 @@
+package uconfig_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/omeid/uconfig"
+	"github.com/omeid/uconfig/plugins/defaults"
+	"github.com/omeid/uconfig/plugins/env"
+	"github.com/omeid/uconfig/plugins/file"
+	"github.com/omeid/uconfig/plugins/flag"
+	"github.com/omeid/uconfig/plugins/secret"
+)
+
+type fRequiredDB struct {
+	Host     string `uconfig:".Address" required:""`
+	Password string `secret:"" required:""`
+	Name     string `default:"app" required:""`
+}
+
+type fRequired struct {
+	DB fRequiredDB
+}
+
+func TestRequiredSatisfiedByAnySource(t *testing.T) {
+	t.Setenv("DB_ADDRESS", "db-host")
+
+	source := func(name string) (string, error) { return "top secret token", nil }
+
+	conf := uconfig.New[fRequired](defaults.New(), secret.New(source), env.New())
+
+	value, err := conf.Parse()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expect := &fRequired{
+		DB: fRequiredDB{
+			Host:     "db-host",
+			Password: "top secret token",
+			Name:     "app",
+		},
+	}
+
+	if diff := cmp.Diff(expect, value); diff != "" {
+		t.Error(diff)
+	}
+}
+
+func TestRequiredSatisfiedByFile(t *testing.T) {
+	srcJSON := `{"DB": {"Host": "db-host", "Password": "from-file"}}`
+
+	conf := uconfig.New[fRequired](
+		defaults.New(),
+		file.NewReader(bytes.NewReader([]byte(srcJSON)), "config.json", json.Unmarshal),
+	)
+
+	_, err := conf.Parse()
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestRequiredSatisfiedByZeroValue(t *testing.T) {
+	type Config struct {
+		Enabled bool `required:""`
+		Port    int  `json:"port" required:""`
+	}
+
+	// the values are the same as the zero values.
+	srcJSON := `{"Enabled": false, "port": 0}`
+
+	conf := uconfig.New[Config](
+		file.NewReader(bytes.NewReader([]byte(srcJSON)), "config.json", json.Unmarshal),
+	)
+
+	_, err := conf.Parse()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	conf = uconfig.New[Config](
+		file.NewReader(bytes.NewReader([]byte(`{}`)), "config.json", json.Unmarshal),
+	)
+
+	_, err = conf.Parse()
+	expect := "Enabled (file key Enabled or enabled): missing required field\n" +
+		"Port (file key port): missing required field"
+
+	if err == nil || err.Error() != expect {
+		t.Errorf("expected (%s) but got (%v)", expect, err)
+	}
+}
+
+func TestRequiredMissing(t *testing.T) {
+	source := func(name string) (string, error) { return "", nil }
+
+	files := uconfig.Files{
+		{Path: file.Relative("testdata/does-not-exist.json"), Unmarshal: json.Unmarshal, Optional: true},
+	}
+
+	conf := uconfig.New[fRequired](
+		append(
+			files.Plugins(),
+			defaults.New(),
+			secret.New(source),
+			env.New(),
+			flag.New("testing", flag.ContinueOnError, nil),
+		)...,
+	)
+
+	_, err := conf.Parse()
+	if err == nil {
+		t.Fatal("expected error for missing required fields, got nil")
+	}
+
+	expect := "DB.Address (flag -db-address, env DB_ADDRESS, file key DB.Host or db.host): missing required field\n" +
+		"DB.Password (flag -db-password, env DB_PASSWORD, secret DB_PASSWORD, file key DB.Password or db.password): missing required field"
+
+	if err.Error() != expect {
+		t.Errorf("expected (%s) but got (%s)", expect, err)
+	}
+}
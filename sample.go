@@ -0,0 +1,98 @@
+package uconfig
+
+import (
+	"io"
+	"strings"
+
+	"github.com/omeid/uconfig/flat"
+	"github.com/omeid/uconfig/plugins"
+	"github.com/omeid/uconfig/plugins/defaults"
+	"github.com/omeid/uconfig/plugins/env"
+	"github.com/omeid/uconfig/plugins/flag"
+	"github.com/omeid/uconfig/plugins/secret"
+)
+
+// samplePlaceholder is the value of secret fields in a sample.
+const samplePlaceholder = "<secret>"
+
+// Sample writes a sample config file for C in the given format, every
+// field is set to the value of its default tag and preceded by its usage
+// and the flag, env, and secret names as comments. Secret fields are set
+// to a placeholder instead. JSON has no comments, so only values are
+// written. The fields are named and nested the way the unmarshalers of
+// the format see them, see flat.Field.Path, so that the sample loads as
+// is. Durations are written as text, e.g. 5s, which yaml decodes, but
+// encoding/json only takes nanoseconds.
+func Sample[C any](w io.Writer, format Format) error {
+	fields, err := flat.View(new(C))
+	if err != nil {
+		return err
+	}
+
+	defaultsPlugin := defaults.New()
+	ps := []plugins.Plugin{
+		defaultsPlugin,
+		env.New(),
+		secret.New(nil),
+		flag.New("sample", flag.ContinueOnError, nil),
+	}
+
+	// the plugins only name the fields, only defaults set them.
+	for _, p := range ps {
+		err := p.(plugins.Visitor).Visit(fields)
+		if err != nil {
+			return err
+		}
+	}
+
+	err = defaultsPlugin.Parse()
+	if err != nil {
+		return err
+	}
+
+	// the command is an argument, not part of the config.
+	sample := make(flat.Fields, 0, len(fields))
+	for _, f := range fields {
+		if !flag.IsCommand(f) {
+			sample = append(sample, f)
+		}
+	}
+
+	keys, _ := fileTag(format)
+
+	return dump(w, sample, format, dumpOptions{
+		redact:   sampleValue,
+		comments: sampleComments,
+		keys:     keys,
+	})
+}
+
+func sampleValue(f flat.Field) (string, bool) {
+	_, ok := f.Tag(secretTag)
+	return samplePlaceholder, ok
+}
+
+func sampleComments(f flat.Field) []string {
+	var comments []string
+
+	if usage, ok := f.Tag(usageTag); ok && usage != "" {
+		comments = append(comments, usage)
+	}
+
+	if ways := supplyWays(f, false); len(ways) > 0 {
+		comments = append(comments, strings.Join(ways, ", "))
+	}
+
+	var rules []string
+	if _, ok := f.Tag(requiredTag); ok {
+		rules = append(rules, requiredTag)
+	}
+	if rule, ok := f.Tag(validateTag); ok && rule != "" {
+		rules = append(rules, rule)
+	}
+	if len(rules) > 0 {
+		comments = append(comments, strings.Join(rules, ", "))
+	}
+
+	return comments
+}
@@ -0,0 +1,121 @@
+package uconfig_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/omeid/uconfig"
+	"github.com/omeid/uconfig/internal/f"
+	"github.com/omeid/uconfig/plugins/defaults"
+	"github.com/omeid/uconfig/plugins/file"
+)
+
+func TestSample(t *testing.T) {
+	type Config struct {
+		Mode  string `flag:",command" default:"run"`
+		Port  int    `default:"8080" usage:"port to listen on" validate:"min=1"`
+		Token string `secret:"" required:""`
+		Redis f.Redis
+	}
+
+	tests := []struct {
+		format uconfig.Format
+		expect string
+	}{
+		{
+			format: uconfig.FormatYAML,
+			expect: `# port to listen on
+# flag -port, env PORT
+# min=1
+port: 8080
+# flag -token, env TOKEN, secret TOKEN
+# required
+token: "<secret>"
+redis:
+  # flag -redis-address, env REDIS_ADDRESS
+  host: ""
+  # flag -redis-port, env REDIS_PORT
+  port: 0
+`,
+		},
+		{
+			format: uconfig.FormatEnv,
+			expect: `# port to listen on
+# flag -port, env PORT
+# min=1
+PORT=8080
+# flag -token, env TOKEN, secret TOKEN
+# required
+TOKEN=<secret>
+# flag -redis-address, env REDIS_ADDRESS
+REDIS_ADDRESS=
+# flag -redis-port, env REDIS_PORT
+REDIS_PORT=0
+`,
+		},
+		{
+			format: uconfig.FormatJSON,
+			expect: `{
+  "Port": 8080,
+  "Token": "<secret>",
+  "Redis": {
+    "Host": "",
+    "Port": 0
+  }
+}
+`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.format), func(t *testing.T) {
+			var buf bytes.Buffer
+			err := uconfig.Sample[Config](&buf, tt.format)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if diff := cmp.Diff(tt.expect, buf.String()); diff != "" {
+				t.Error(diff)
+			}
+		})
+	}
+}
+
+func TestSampleLoads(t *testing.T) {
+	type Server struct {
+		Host string `default:"localhost" uconfig:".Address"`
+		Port int    `default:"8080" json:"port"`
+	}
+
+	type Config struct {
+		f.Anon
+		Level  string         `default:"info"`
+		Tags   []string       `default:"a,b"`
+		Limits map[string]int `default:"x:1,y:2"`
+		Server Server
+		Debug  bool `json:"-"`
+	}
+
+	var buf bytes.Buffer
+	err := uconfig.Sample[Config](&buf, uconfig.FormatJSON)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expect, err := uconfig.New[Config](defaults.New()).Parse()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	value, err := uconfig.New[Config](file.NewReader(&buf, "sample.json", json.Unmarshal)).Parse()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if diff := cmp.Diff(expect, value); diff != "" {
+		t.Error(diff)
+	}
+}
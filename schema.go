@@ -0,0 +1,302 @@
+package uconfig
+
+import (
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/omeid/uconfig/flat"
+	"github.com/omeid/uconfig/plugins"
+	"github.com/omeid/uconfig/plugins/defaults"
+)
+
+const schemaDialect = "https://json-schema.org/draft/2020-12/schema"
+
+// jsonSchema is the subset of JSON Schema used to describe a config.
+type jsonSchema struct {
+	Schema      string `json:"$schema,omitempty"`
+	Type        any    `json:"type,omitempty"`
+	Description string `json:"description,omitempty"`
+	Default     any    `json:"default,omitempty"`
+
+	Enum    []any       `json:"enum,omitempty"`
+	Format  string      `json:"format,omitempty"`
+	Pattern string      `json:"pattern,omitempty"`
+	Not     *jsonSchema `json:"not,omitempty"`
+	Const   any         `json:"const,omitempty"`
+
+	Minimum       *float64 `json:"minimum,omitempty"`
+	Maximum       *float64 `json:"maximum,omitempty"`
+	MinLength     *int     `json:"minLength,omitempty"`
+	MaxLength     *int     `json:"maxLength,omitempty"`
+	MinItems      *int     `json:"minItems,omitempty"`
+	MaxItems      *int     `json:"maxItems,omitempty"`
+	MinProperties *int     `json:"minProperties,omitempty"`
+	MaxProperties *int     `json:"maxProperties,omitempty"`
+
+	Items                *jsonSchema            `json:"items,omitempty"`
+	Properties           map[string]*jsonSchema `json:"properties,omitempty"`
+	AdditionalProperties *jsonSchema            `json:"additionalProperties,omitempty"`
+	Required             []string               `json:"required,omitempty"`
+}
+
+// Schema returns a JSON Schema (draft 2020-12) of C as seen by the
+// unmarshalers of the file format, FormatJSON, FormatYAML, or FormatTOML.
+// The properties are named and nested by the struct tag of the format,
+// see flat.Field.Path, and the fields the unmarshalers skip are left out.
+// The types are those the plugins can set, the default tag is used as
+// the default, usage as the description, fields tagged with required are
+// required, and the rules of the validate tag are mapped to constraints
+// where JSON Schema has an equivalent.
+func Schema[C any](format Format) ([]byte, error) {
+	tag, ok := fileTag(format)
+	if !ok {
+		return nil, fmt.Errorf("uconfig: unsupported schema format %q", format)
+	}
+
+	fields, err := flat.View(new(C))
+	if err != nil {
+		return nil, err
+	}
+
+	// set the defaults so they are typed like the fields.
+	plug := defaults.New()
+	err = plug.(plugins.Visitor).Visit(fields)
+	if err != nil {
+		return nil, err
+	}
+	err = plug.Parse()
+	if err != nil {
+		return nil, err
+	}
+
+	root := &jsonSchema{Schema: schemaDialect, Type: "object"}
+
+	for _, f := range fields {
+		value := f.Interface()
+		if value == nil {
+			// unexported, file unmarshalers never set those.
+			continue
+		}
+
+		path := f.Path(tag)
+		if path == nil {
+			continue
+		}
+
+		parent := root
+		for _, name := range path[:len(path)-1] {
+			parent = parent.property(name, &jsonSchema{Type: "object"})
+		}
+
+		s := typeSchema(reflect.TypeOf(value))
+		s.Description, _ = f.Tag(usageTag)
+
+		if _, ok := f.Tag("default"); ok {
+			s.Default = schemaValue(reflect.ValueOf(value))
+		}
+
+		if rules, ok := f.Tag(validateTag); ok {
+			schemaRules(s, rules)
+		}
+
+		name := path[len(path)-1]
+		parent.property(name, s)
+
+		if _, ok := f.Tag(requiredTag); ok {
+			parent.Required = append(parent.Required, name)
+		}
+	}
+
+	return json.MarshalIndent(root, "", "  ")
+}
+
+// property returns the property by name, adding s if there is none.
+func (s *jsonSchema) property(name string, prop *jsonSchema) *jsonSchema {
+	if s.Properties == nil {
+		s.Properties = map[string]*jsonSchema{}
+	}
+
+	if existing, ok := s.Properties[name]; ok {
+		return existing
+	}
+
+	s.Properties[name] = prop
+	return prop
+}
+
+var textUnmarshalerType = reflect.TypeOf(new(encoding.TextUnmarshaler)).Elem()
+
+// typeSchema describes the values of t the way flat.Field.Set accepts them.
+func typeSchema(t reflect.Type) *jsonSchema {
+	if t.Implements(textUnmarshalerType) || reflect.PointerTo(t).Implements(textUnmarshalerType) {
+		return &jsonSchema{Type: "string"}
+	}
+
+	if t == durationType {
+		// decoders take either nanoseconds or, like yaml, "1m30s".
+		return &jsonSchema{Type: []string{"integer", "string"}}
+	}
+
+	switch t.Kind() {
+	case reflect.Pointer:
+		return typeSchema(t.Elem())
+	case reflect.String:
+		return &jsonSchema{Type: "string"}
+	case reflect.Bool:
+		return &jsonSchema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return &jsonSchema{Type: "integer"}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		zero := 0.0
+		return &jsonSchema{Type: "integer", Minimum: &zero}
+	case reflect.Float32, reflect.Float64:
+		return &jsonSchema{Type: "number"}
+	case reflect.Slice, reflect.Array:
+		return &jsonSchema{Type: "array", Items: typeSchema(t.Elem())}
+	case reflect.Map:
+		return &jsonSchema{Type: "object", AdditionalProperties: typeSchema(t.Elem())}
+	}
+
+	// anything goes.
+	return &jsonSchema{}
+}
+
+// schemaValue converts the value to its JSON form.
+func schemaValue(rv reflect.Value) any {
+	for rv.Kind() == reflect.Pointer {
+		if rv.IsNil() {
+			return nil
+		}
+		rv = rv.Elem()
+	}
+
+	if rv.Kind() == reflect.Map && !isText(rv) {
+		values := make(map[string]any, rv.Len())
+		iter := rv.MapRange()
+		for iter.Next() {
+			values[formatValue(iter.Key().Interface())] = dumpValue(iter.Value())
+		}
+		return values
+	}
+
+	return dumpValue(rv)
+}
+
+// schemaRules maps the validate rules to their JSON Schema equivalents,
+// rules that have none are left out.
+func schemaRules(s *jsonSchema, rules string) {
+	for rules != "" {
+		var rule string
+		if strings.HasPrefix(rules, "regexp=") {
+			rule, rules = rules, ""
+		} else {
+			rule, rules, _ = strings.Cut(rules, ",")
+		}
+
+		name, arg, _ := strings.Cut(strings.TrimSpace(rule), "=")
+
+		switch name {
+		case "min", "max":
+			schemaBound(s, name, arg)
+
+		case "nonzero":
+			schemaNonZero(s)
+
+		case "oneof":
+			for _, option := range strings.Split(arg, "|") {
+				s.Enum = append(s.Enum, schemaOption(s, option))
+			}
+
+		case "regexp":
+			s.Pattern = arg
+
+		case "url":
+			s.Format = "uri"
+
+		case "hostport":
+			if s.Pattern == "" {
+				s.Pattern = `^.*:[0-9]+$`
+			}
+		}
+	}
+}
+
+func schemaBound(s *jsonSchema, rule string, arg string) {
+	switch s.Type {
+	case "integer", "number":
+		limit, err := strconv.ParseFloat(arg, 64)
+		if err != nil {
+			return
+		}
+		if rule == "min" {
+			s.Minimum = &limit
+		} else {
+			s.Maximum = &limit
+		}
+		return
+	}
+
+	limit, err := strconv.Atoi(arg)
+	if err != nil {
+		return
+	}
+
+	var lower, upper **int
+	switch s.Type {
+	case "string":
+		lower, upper = &s.MinLength, &s.MaxLength
+	case "array":
+		lower, upper = &s.MinItems, &s.MaxItems
+	case "object":
+		lower, upper = &s.MinProperties, &s.MaxProperties
+	default:
+		return
+	}
+
+	if rule == "min" {
+		*lower = &limit
+	} else {
+		*upper = &limit
+	}
+}
+
+func schemaNonZero(s *jsonSchema) {
+	one := 1
+
+	switch s.Type {
+	case "string":
+		s.MinLength = &one
+	case "array":
+		s.MinItems = &one
+	case "object":
+		s.MinProperties = &one
+	case "integer", "number":
+		s.Not = &jsonSchema{Const: 0}
+	case "boolean":
+		s.Const = true
+	}
+}
+
+// schemaOption types the oneof option like the field.
+func schemaOption(s *jsonSchema, option string) any {
+	switch s.Type {
+	case "integer":
+		if v, err := strconv.ParseInt(option, 0, 64); err == nil {
+			return v
+		}
+	case "number":
+		if v, err := strconv.ParseFloat(option, 64); err == nil {
+			return v
+		}
+	case "boolean":
+		if v, err := strconv.ParseBool(option); err == nil {
+			return v
+		}
+	}
+
+	return option
+}
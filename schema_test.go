@@ -0,0 +1,146 @@
+package uconfig_test
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/omeid/uconfig"
+	"github.com/omeid/uconfig/internal/f"
+)
+
+func TestSchema(t *testing.T) {
+	type Server struct {
+		Host    string        `default:"localhost" usage:"the host to bind to" validate:"nonzero"`
+		Port    uint16        `default:"8080" validate:"min=1,max=65535" required:""`
+		Timeout time.Duration `default:"5s"`
+	}
+
+	type Config struct {
+		f.Anon
+		Level   string   `default:"info" validate:"oneof=debug|info|error"`
+		Workers int      `validate:"oneof=1|2|4"`
+		Tags    []string `validate:"min=1"`
+		Limits  map[string]float64
+		Name    string `uconfig:"Alias" validate:"regexp=^[a-z]+$"`
+		Server  Server
+		Dir     *f.ReadableDirection
+		hidden  string
+	}
+
+	schema, err := uconfig.Schema[Config](uconfig.FormatJSON)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var got any
+	err = json.Unmarshal(schema, &got)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expect := map[string]any{
+		"$schema": "https://json-schema.org/draft/2020-12/schema",
+		"type":    "object",
+		"properties": map[string]any{
+			"Version": map[string]any{"type": "string"},
+			"Level": map[string]any{
+				"type":    "string",
+				"default": "info",
+				"enum":    []any{"debug", "info", "error"},
+			},
+			"Workers": map[string]any{
+				"type": "integer",
+				"enum": []any{1.0, 2.0, 4.0},
+			},
+			"Tags": map[string]any{
+				"type":     "array",
+				"items":    map[string]any{"type": "string"},
+				"minItems": 1.0,
+			},
+			"Limits": map[string]any{
+				"type":                 "object",
+				"additionalProperties": map[string]any{"type": "number"},
+			},
+			"Name": map[string]any{
+				"type":    "string",
+				"pattern": "^[a-z]+$",
+			},
+			"Server": map[string]any{
+				"type": "object",
+				"properties": map[string]any{
+					"Host": map[string]any{
+						"type":        "string",
+						"description": "the host to bind to",
+						"default":     "localhost",
+						"minLength":   1.0,
+					},
+					"Port": map[string]any{
+						"type":    "integer",
+						"default": 8080.0,
+						"minimum": 1.0,
+						"maximum": 65535.0,
+					},
+					"Timeout": map[string]any{
+						"type":    []any{"integer", "string"},
+						"default": "5s",
+					},
+				},
+				"required": []any{"Port"},
+			},
+			"Dir": map[string]any{"type": "string"},
+		},
+	}
+
+	if diff := cmp.Diff(expect, got); diff != "" {
+		t.Error(diff)
+	}
+}
+
+func TestSchemaTags(t *testing.T) {
+	type Redis struct {
+		Host string `json:"host" yaml:"address"`
+		Port int    `required:""`
+	}
+
+	type Config struct {
+		f.Anon `json:"anon"`
+		Redis  Redis
+		Token  string `json:"-" yaml:"-"`
+	}
+
+	expect := map[uconfig.Format]string{
+		uconfig.FormatJSON: `{"Redis":{"properties":{"Port":{},"host":{}}},"anon":{"properties":{"Version":{}}}}`,
+		uconfig.FormatYAML: `{"anon":{"properties":{"version":{}}},"redis":{"properties":{"address":{},"port":{}}}}`,
+	}
+
+	for format, expect := range expect {
+		schema, err := uconfig.Schema[Config](format)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		type object struct {
+			Properties map[string]struct{} `json:"properties,omitempty"`
+		}
+		var got struct {
+			Properties map[string]object `json:"properties"`
+		}
+		err = json.Unmarshal(schema, &got)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		keys, _ := json.Marshal(got.Properties)
+		if diff := cmp.Diff(expect, string(keys)); diff != "" {
+			t.Errorf("%s: %s", format, diff)
+		}
+	}
+
+	_, err := uconfig.Schema[Config](uconfig.FormatEnv)
+	expectErr := `uconfig: unsupported schema format "env"`
+	if err == nil || err.Error() != expectErr {
+		t.Errorf("expected (%s) but got (%v)", expectErr, err)
+	}
+}
@@ -0,0 +1,100 @@
+package uconfig_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/omeid/uconfig"
+	"github.com/omeid/uconfig/plugins/defaults"
+	"github.com/omeid/uconfig/plugins/file"
+)
+
+type fSnapshot struct {
+	Name  string   `default:"app"`
+	Port  int      `default:"80"`
+	Hosts []string `default:"a,b"`
+}
+
+func TestParseSnapshots(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+
+	err := os.WriteFile(path, []byte(`{"Name": "v1", "Hosts": ["c"]}`), 0o644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	conf := uconfig.New[fSnapshot](
+		defaults.New(),
+		file.New(path, json.Unmarshal, file.Config{}),
+	)
+
+	first, err := conf.Parse()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expect := &fSnapshot{Name: "v1", Port: 80, Hosts: []string{"c"}}
+	if diff := cmp.Diff(expect, first); diff != "" {
+		t.Fatal(diff)
+	}
+
+	// a broken reload must not touch the published snapshot.
+	err = os.WriteFile(path, []byte(`{"Name": "v2", "Port": "bad"}`), 0o644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = conf.Parse()
+	if err == nil {
+		t.Fatal("expected error for bad file, got nil")
+	}
+
+	if diff := cmp.Diff(expect, first); diff != "" {
+		t.Fatal(diff)
+	}
+
+	err = os.WriteFile(path, []byte(`{"Port": 8080}`), 0o644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	second, err := conf.Parse()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if first == second {
+		t.Fatal("expected a new snapshot for each parse")
+	}
+
+	// nothing from the failed parse leaks into the next one.
+	expectSecond := &fSnapshot{Name: "app", Port: 8080, Hosts: []string{"a", "b"}}
+	if diff := cmp.Diff(expectSecond, second); diff != "" {
+		t.Error(diff)
+	}
+
+	if diff := cmp.Diff(expect, first); diff != "" {
+		t.Error(diff)
+	}
+}
+
+func TestParseSnapshotsReader(t *testing.T) {
+	src := bytes.NewReader([]byte(`{"Name": "from-stream"}`))
+
+	conf := uconfig.New[fSnapshot](file.NewReader(src, "[stream]", json.Unmarshal))
+
+	for i := 0; i < 2; i++ {
+		value, err := conf.Parse()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if value.Name != "from-stream" {
+			t.Fatalf("parse %d: expected from-stream, got %q", i, value.Name)
+		}
+	}
+}
@@ -5,7 +5,9 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"io"
 	"os"
+	"sync"
 
 	"github.com/omeid/uconfig/flat"
 	"github.com/omeid/uconfig/plugins"
@@ -14,6 +16,13 @@ import (
 // ErrUsage is returned when the user requests usage (e.g. -h flag).
 var ErrUsage = plugins.ErrUsage
 
+// ErrCompletion is returned when the user requests shell completion
+// (e.g. the __complete argument), which has already been written.
+var ErrCompletion = plugins.ErrCompletion
+
+// ErrClosed is returned when parsing a config that has been closed.
+var ErrClosed = errors.New("uconfig: config is closed")
+
 // PluginProvider is implemented by types that can provide plugins.
 // Both file.Files and watchfile.Files implement this interface,
 // allowing Classic and Load to accept either.
@@ -24,95 +33,350 @@ type PluginProvider interface {
 // Config is the config manager.
 type Config[C any] interface {
 	// Parse will call the parse method of all the added plugins in the order
-	// they were registered. It returns early as soon as any plugin fails.
+	// they were registered. Each parse builds a fresh config value which is
+	// only returned when it succeeds, previously returned values are never
+	// modified. Once all plugins have run, fields tagged with
+	// required that were not set by any plugin and fields that fail the
+	// rules in their validate tag are checked.
+	// All failures are reported together as a *ParseError.
 	// You must call this before using the config value.
 	Parse() (*C, error)
 
+	// ParseContext is like Parse, but plugins that implement
+	// plugins.ContextPlugin are given ctx, and the parse stops
+	// with ctx's error once it is done.
+	ParseContext(ctx context.Context) (*C, error)
+
 	// Run calls Parse and checks the error to see if usage was requested,
-	// otherwise prints the error and usage and exits with os.Exit(1).
+	// the config to be printed (e.g. -print-config flag), or the version
+	// (e.g. -version flag, see Version), otherwise
+	// prints the error and usage and exits with os.Exit(1).
 	Run() *C
 
 	// Usage provides a simple usage message based on the meta data registered
 	// by the plugins.
 	Usage()
 
+	// WriteUsage writes the usage message to w in the given format,
+	// the same fields as Usage as text, a man page, or Markdown.
+	WriteUsage(w io.Writer, format UsageFormat) error
+
+	// SetUsageOptions sets the name, description, examples, and
+	// layout of the usage message, see UsageOptions.
+	SetUsageOptions(opts UsageOptions)
+
+	// WriteVersion writes the name and version of the program, and the
+	// module, VCS revision, commit time, and Go version it was built
+	// from, see Version.
+	WriteVersion(w io.Writer) error
+
 	// Watch calls Parse for the initial configuration, then calls fn.
-	// When any plugin that implements Updater signals a change, fn's
-	// context is cancelled, the config is re-parsed, and fn is called
-	// again with the new value.
+	// When any plugin that implements Updater signals a change, the
+	// config is re-parsed, fn's context is cancelled, and fn is called
+	// again with the new value. If the re-parse fails, fn is left running
+	// with the current value until the next change.
 	// If no plugins implement Updater, fn is called once.
+	// Parses are given ctx, see ParseContext.
 	//
 	// fn should block (e.g. <-ctx.Done()) to stay alive until a
 	// config change. When fn returns, Watch exits with fn's error.
 	Watch(ctx context.Context, fn func(ctx context.Context, c *C) error) error
+
+	// WatchDiff is like Watch, but fn also receives the previous config
+	// and the fields that have changed, both are nil on the first call.
+	// When a re-parse results in the same values, fn is left running.
+	WatchDiff(ctx context.Context, fn func(ctx context.Context, old *C, new *C, changes []Change) error) error
+
+	// WatchWith is like WatchDiff, but changes to fields tagged with
+	// reload:"hot" are applied through opts.OnHotReload without
+	// restarting fn, and changes to fields tagged with reload:"restart"
+	// are reported through opts.OnRestartRequired instead of restarting
+	// fn. Watch and WatchDiff honour the reload tag with empty options.
+	WatchWith(ctx context.Context, opts WatchOptions[C], fn func(ctx context.Context, old *C, new *C, changes []Change) error) error
+
+	// Dump writes the config of the last successful Parse to w in the
+	// given format, with the values of fields tagged with secret or
+	// sensitive masked.
+	Dump(w io.Writer, format Format) error
+
+	// Live parses the config and keeps it up to date in the background,
+	// see Live for details.
+	Live(ctx context.Context) (*Live[C], error)
+
+	// Command returns the names of the command selected by the last
+	// successful Parse and its parents, e.g. [db migrate up], or nil
+	// when no command was selected, see Commands.
+	Command() []string
+
+	// Close closes all the plugins that implement plugins.Closer and
+	// returns their errors joined. It is called once Watch, WatchDiff,
+	// WatchWith return or a Live is done. Once closed, the config can
+	// no longer be parsed, calling Close again is a no-op.
+	Close() error
+
+	// Sources returns the provenance of every field from the last successful
+	// Parse keyed by the field name. Each plugin that set a field is listed in
+	// the order it did so, the last one being the effective value.
+	// Walkers are listed for the fields they change, and for those under
+	// the keys of their source when they implement plugins.Keyer, like
+	// the file plugin does.
+	Sources() map[string][]flat.Source
+
+	// Explain writes the provenance of every field to w, one line per
+	// plugin that set it, with secret values masked.
+	Explain(w io.Writer) error
 }
 
 // New returns a new Config. The conf must be a pointer to a struct.
 func New[C any](ps ...plugins.Plugin) Config[C] {
-	conf := new(C)
-	fields, err := flat.View(conf)
+	fields, err := flat.View(new(C))
 
 	return &config[C]{
 		err:     err,
-		conf:    conf,
-		fields:  fields,
+		attempt: fields,
 		plugins: ps,
 	}
 }
 
 type config[C any] struct {
 	plugins []plugins.Plugin
-	conf    *C
-	fields  flat.Fields
 
-	err error // lazy error
+	// mu serialises parses and guards the snapshot, as
+	// Watch and Live parse in the background.
+	mu sync.Mutex
+
+	// conf and fields are the snapshot of the last successful
+	// parse, they are never modified once published.
+	conf   *C
+	fields flat.Fields
+
+	// attempt is the fields of the last parse, successful or not,
+	// it is what usage describes.
+	attempt flat.Fields
+
+	// command is the command selected by the snapshot.
+	command []string
+
+	usageOpts UsageOptions
+
+	closed bool
+	err    error // lazy error
 }
 
 func (c *config[C]) Parse() (*C, error) {
+	return c.ParseContext(context.Background())
+}
+
+func (c *config[C]) ParseContext(ctx context.Context) (*C, error) {
+	conf, _, err := c.parse(ctx)
+	return conf, err
+}
+
+// parse runs all the plugins and publishes the result
+// as the current snapshot if it succeeds.
+func (c *config[C]) parse(ctx context.Context) (*C, flat.Fields, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
 	if c.err != nil {
-		return nil, c.err
+		return nil, nil, c.err
 	}
 
-	// first setup plugins.
-	for _, plug := range c.plugins {
-		switch plug := plug.(type) {
+	if c.closed {
+		return nil, nil, ErrClosed
+	}
 
-		case plugins.Visitor:
-			err := plug.Visit(c.fields)
-			if err != nil {
-				return nil, err
-			}
+	// parse into a fresh value so that a failing parse never
+	// leaves the previous snapshot partially updated.
+	conf := new(C)
+	fields, err := flat.View(conf)
+	if err != nil {
+		return nil, nil, err
+	}
+	c.attempt = fields
 
-		case plugins.Walker:
-			err := plug.Walk(c.conf)
-			if err != nil {
-				return nil, err
+	errs := &ParseError{}
+	failed := make([]bool, len(c.plugins))
+
+	// first setup plugins, those that set up the others go first.
+	for _, first := range []bool{true, false} {
+		for i, plug := range c.plugins {
+			if setsUp(plug) != first {
+				continue
 			}
 
-		case plugins.Extension:
-			err := plug.Extend(c.plugins)
+			err := c.setup(plug, conf, fields)
 			if err != nil {
-				return nil, err
+				errs.add(err)
+				failed[i] = true
 			}
+		}
+	}
 
-		default:
-			return nil, fmt.Errorf("unsupported plugins. expecting a walker or visitor")
+	ready := make([]plugins.Plugin, 0, len(c.plugins))
+	for i, plug := range c.plugins {
+		if !failed[i] {
+			ready = append(ready, plug)
 		}
 	}
 
-	for _, p := range c.plugins {
+	for _, p := range ready {
+		// there is no point carrying on, the
+		// result would be discarded anyway.
+		if err := ctx.Err(); err != nil {
+			return nil, nil, err
+		}
 
-		err := p.Parse()
+		// walkers set the config as a whole, so we
+		// work out what they have changed ourselves.
+		var before []any
+		if isWalker(p) {
+			before = snapshot(fields)
+		}
+
+		err := parsePlugin(ctx, p)
 		if err != nil {
-			return nil, err
+			errs.add(err)
+			continue
+		}
+
+		if before != nil {
+			record(p, fields, before)
+		}
+	}
+
+	if err := ctx.Err(); err != nil {
+		return nil, nil, err
+	}
+
+	// fields that have failed already are not checked again,
+	// neither are those of the commands that are not selected.
+	skip := errs.failed()
+	command := selectedCommand(c.plugins)
+	for name := range inactiveFields(c.commandTree(), command) {
+		skip[name] = true
+	}
+
+	errs.add(
+		required(fields, c.plugins, skip),
+		validate(fields, skip),
+	)
+
+	err = errs.err()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	c.conf, c.fields, c.command = conf, fields, command
+
+	return conf, fields, nil
+}
+
+// parsePlugin prefers ParseContext when the plugin supports it.
+func parsePlugin(ctx context.Context, p plugins.Plugin) error {
+	if cp, ok := p.(plugins.ContextPlugin); ok {
+		return cp.ParseContext(ctx)
+	}
+	return p.Parse()
+}
+
+func (c *config[C]) Close() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.closed {
+		return nil
+	}
+	c.closed = true
+
+	var errs error
+	for _, p := range c.plugins {
+		if closer, ok := p.(plugins.Closer); ok {
+			errs = errors.Join(errs, closer.Close())
 		}
 	}
 
-	return c.conf, nil
+	return errs
+}
+
+// closeWith closes the config and adds its error to err.
+func (c *config[C]) closeWith(err error) error {
+	cerr := c.Close()
+	if cerr == nil {
+		return err
+	}
+	return errors.Join(err, cerr)
+}
+
+// publish makes conf the current snapshot.
+func (c *config[C]) publish(conf *C, fields flat.Fields) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.conf, c.fields = conf, fields
+}
+
+// current returns the fields of the current snapshot.
+func (c *config[C]) current() flat.Fields {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.fields
+}
+
+// lastAttempt returns the fields of the last parse.
+func (c *config[C]) lastAttempt() flat.Fields {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.attempt
+}
+
+func (c *config[C]) setup(plug plugins.Plugin, conf *C, fields flat.Fields) error {
+	switch plug := plug.(type) {
+
+	case plugins.Visitor:
+		return plug.Visit(fields)
+
+	case plugins.Walker:
+		return plug.Walk(conf)
+
+	case plugins.Extension:
+		return plug.Extend(c.plugins)
+
+	default:
+		return fmt.Errorf("unsupported plugins. expecting a walker or visitor")
+	}
 }
 
 func (c *config[C]) Run() *C {
 	conf, err := c.Parse()
+
+	if errors.Is(err, ErrCompletion) {
+		os.Exit(0)
+	}
+
+	if errors.Is(err, ErrVersion) {
+		err := c.WriteVersion(os.Stdout)
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+		os.Exit(0)
+	}
+
+	var printConfig *plugins.PrintConfig
+	if errors.As(err, &printConfig) {
+		// print what has been resolved, even if
+		// the config is not valid.
+		err := dump(os.Stdout, c.lastAttempt(), Format(printConfig.Format), dumpOptions{redact: maskValue})
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+		os.Exit(0)
+	}
+
 	if err != nil {
 		usageRequest := errors.Is(err, ErrUsage)
 		ret := 1
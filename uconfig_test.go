@@ -11,6 +11,8 @@ import (
 	"github.com/omeid/uconfig/flat"
 	"github.com/omeid/uconfig/internal/f"
 	"github.com/omeid/uconfig/plugins"
+	"github.com/omeid/uconfig/plugins/env"
+	"github.com/omeid/uconfig/plugins/flag"
 )
 
 type BadPlugin interface {
@@ -123,6 +125,44 @@ func TestExtensionError(t *testing.T) {
 	}
 }
 
+func TestExtensionRegisteredAfter(t *testing.T) {
+	type Config struct {
+		Port  int
+		Debug bool
+	}
+
+	t.Setenv("PORT", "80")
+	t.Setenv("APP_PORT", "8080")
+
+	conf := uconfig.New[Config](
+		env.New(),
+		flag.New("testing", flag.ContinueOnError, []string{"-version"}),
+		uconfig.EnvPrefix("APP_"),
+		uconfig.Version("1.2.3"),
+	)
+
+	// every parse must see the extensions, not just those after the first.
+	for i := 0; i < 2; i++ {
+		_, err := conf.Parse()
+		if !errors.Is(err, uconfig.ErrVersion) {
+			t.Fatalf("parse %d: expected ErrVersion, got %v", i, err)
+		}
+	}
+
+	conf = uconfig.New[Config](env.New(), uconfig.EnvPrefix("APP_"))
+
+	for i := 0; i < 2; i++ {
+		value, err := conf.Parse()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if value.Port != 8080 {
+			t.Errorf("parse %d: expected the prefixed port, got %d", i, value.Port)
+		}
+	}
+}
+
 // --- Watch / Updater tests ---
 
 type simpleConfig struct {
@@ -130,6 +170,114 @@ type simpleConfig struct {
 }
 
 // testUpdater is a fake Extension+Updater for testing Watch.
+type ctxKey struct{}
+
+// contextPlugin records which of its parse methods was called.
+type contextPlugin struct {
+	parsed bool
+	value  any
+}
+
+func (p *contextPlugin) Extend([]plugins.Plugin) error { return nil }
+func (p *contextPlugin) Parse() error                  { p.parsed = true; return nil }
+
+func (p *contextPlugin) ParseContext(ctx context.Context) error {
+	p.value = ctx.Value(ctxKey{})
+	return ctx.Err()
+}
+
+func TestParseContext(t *testing.T) {
+	plug := &contextPlugin{}
+	conf := uconfig.New[f.Config](plug)
+
+	ctx := context.WithValue(context.Background(), ctxKey{}, "value")
+	_, err := conf.ParseContext(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if plug.parsed {
+		t.Error("expected ParseContext to be preferred over Parse")
+	}
+
+	if plug.value != "value" {
+		t.Errorf("expected the plugin to get the context, got %v", plug.value)
+	}
+}
+
+func TestParseContextCancelled(t *testing.T) {
+	conf := uconfig.New[f.Config](&contextPlugin{})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := conf.ParseContext(ctx)
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context.Canceled, got %v", err)
+	}
+}
+
+// closerPlugin counts how many times it has been closed.
+type closerPlugin struct {
+	closed int
+	err    error
+}
+
+func (p *closerPlugin) Extend([]plugins.Plugin) error { return nil }
+func (p *closerPlugin) Parse() error                  { return nil }
+
+func (p *closerPlugin) Close() error {
+	p.closed++
+	return p.err
+}
+
+func TestClose(t *testing.T) {
+	errA, errB := errors.New("a"), errors.New("b")
+	a, b, c := &closerPlugin{err: errA}, &closerPlugin{}, &closerPlugin{err: errB}
+
+	conf := uconfig.New[f.Config](a, b, c)
+
+	err := conf.Close()
+	if !errors.Is(err, errA) || !errors.Is(err, errB) {
+		t.Fatalf("expected both errors, got %v", err)
+	}
+
+	err = conf.Close()
+	if err != nil {
+		t.Fatalf("expected closing again to be a no-op, got %v", err)
+	}
+
+	for i, p := range []*closerPlugin{a, b, c} {
+		if p.closed != 1 {
+			t.Errorf("plugin %d: expected to be closed once, got %d", i, p.closed)
+		}
+	}
+
+	_, err = conf.Parse()
+	if !errors.Is(err, uconfig.ErrClosed) {
+		t.Errorf("expected ErrClosed, got %v", err)
+	}
+}
+
+func TestWatchCloses(t *testing.T) {
+	plug := &closerPlugin{}
+	conf := uconfig.New[f.Config](plug)
+
+	err := conf.Watch(context.Background(), func(ctx context.Context, c *f.Config) error {
+		if plug.closed != 0 {
+			t.Error("expected the plugins to be open while fn runs")
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if plug.closed != 1 {
+		t.Errorf("expected the plugin to be closed once Watch returns, got %d", plug.closed)
+	}
+}
+
 type testUpdater struct {
 	ch chan struct{}
 }
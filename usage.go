@@ -5,7 +5,9 @@ import (
 	"io"
 	"os"
 	"path"
+	"slices"
 	"sort"
+	"strconv"
 	"strings"
 	"text/tabwriter"
 
@@ -13,73 +15,526 @@ import (
 	"github.com/omeid/uconfig/plugins"
 	"github.com/omeid/uconfig/plugins/file"
 	"github.com/omeid/uconfig/plugins/flag"
+	"golang.org/x/term"
 )
 
-const usageTag = "usage"
+const (
+	usageTag  = "usage"
+	hiddenTag = "hidden"
+
+	// the columns of Values.
+	valueColumn  = "value"
+	sourceColumn = "source"
+)
 
 func init() {
 	plugins.RegisterTag(usageTag)
+	plugins.RegisterTag(hiddenTag)
 }
 
 // UsageOutput is the io.Writer used by Usage message printer.
 var UsageOutput io.Writer = os.Stdout
 
+// UsageFormat is the format of a usage message.
+type UsageFormat string
+
+const (
+	// UsageText is the table printed by Usage.
+	UsageText UsageFormat = "text"
+
+	// UsageMan is a roff man page, e.g. for `man app`.
+	UsageMan UsageFormat = "man"
+
+	// UsageMarkdown is a Markdown reference document.
+	UsageMarkdown UsageFormat = "markdown"
+
+	// UsageJSON lists the fields with their type, names, default,
+	// rules, and usage, the commands, and the files as JSON.
+	UsageJSON UsageFormat = "json"
+
+	// UsageYAML is the same as UsageJSON, as YAML.
+	UsageYAML UsageFormat = "yaml"
+)
+
+// UsageOptions customise the usage message, in every format.
+// Fields tagged with hidden are left out of it regardless.
+type UsageOptions struct {
+	// Name is the name of the program, the base of os.Args[0] if empty.
+	Name string
+
+	// Synopsis follows the name on the usage line,
+	// "[flags] [command]" if empty.
+	Synopsis string
+
+	// Version is shown next to the name, the one given
+	// to the Version plugin if empty.
+	Version string
+
+	// Description is shown before the usage line.
+	Description string
+
+	// Examples are listed after the usage line.
+	Examples []string
+
+	// Columns are the metadata shown after the field name, in order,
+	// e.g. flag, env, default, and usage. All of it if empty.
+	// JSON and YAML always have all of it, and are not grouped.
+	Columns []string
+
+	// Group lists the fields of each top level struct under
+	// its name, e.g. Redis.Address and Redis.Port under Redis.
+	Group bool
+
+	// Width is what the usage column and description are wrapped to
+	// in text, taken from the COLUMNS environment variable if zero, or
+	// the size of the terminal the usage is written to. Nothing is
+	// wrapped if it is negative, or neither of them is available.
+	Width int
+
+	// Color makes the headings of the text bold, unless the
+	// NO_COLOR environment variable is set.
+	Color bool
+
+	// Values adds the value and source columns, the value of each
+	// field as of the last parse and the plugin and key that set it,
+	// with the values of secret and sensitive fields masked.
+	Values bool
+}
+
+const defaultSynopsis = "[flags] [command]"
+
 // Usage prints out the current config fields, flags, env vars
 // and any other source and setting.
 func (c *config[C]) Usage() {
-	setUsageMeta(c.fields)
-	headers := getHeaders(c.fields)
+	err := c.WriteUsage(UsageOutput, UsageText)
+	if err != nil {
+		// we are asked for usage which means it is interactive use
+		// and so panicking is acceptable.
+		panic(err)
+	}
+}
 
-	w := tabwriter.NewWriter(UsageOutput, 0, 0, 4, ' ', 0)
-	_, _ = fmt.Fprintf(w, "Usage:\n\t%s [flags] [command]\n", path.Base(os.Args[0]))
-	_, _ = fmt.Fprintf(w, "\nConfigurations:\n")
-	_, _ = fmt.Fprintln(w, strings.ToUpper(strings.Join(headers, "\t")))
+func (c *config[C]) WriteUsage(w io.Writer, format UsageFormat) error {
+	u := c.usage()
 
-	dashes := make([]string, len(headers))
-	for i, f := range headers {
-		n := max(len(f), 5)
-		dashes[i] = strings.Repeat("-", n)
+	switch format {
+	case UsageText:
+		return writeUsageText(w, u)
+	case UsageMan:
+		return writeUsageMan(w, u)
+	case UsageMarkdown:
+		return writeUsageMarkdown(w, u)
+	case UsageJSON:
+		return writeUsageJSON(w, u)
+	case UsageYAML:
+		return writeUsageYAML(w, u)
 	}
-	_, _ = fmt.Fprintln(w, strings.Join(dashes, "\t"))
 
-	sort.SliceStable(c.fields, func(i, j int) bool {
-		return flag.IsCommand(c.fields[j]) // move command to last.
+	return fmt.Errorf("uconfig: unknown usage format %q", format)
+}
+
+func (c *config[C]) SetUsageOptions(opts UsageOptions) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.usageOpts = opts
+}
+
+// usage is what a usage message is made of, whatever its format.
+type usage struct {
+	UsageOptions
+
+	headers  []string
+	fields   []usageField
+	groups   []usageGroup
+	commands []usageCommand
+	files    []string
+}
+
+// usageGroup is the rows of a top level struct, or
+// of the rest of the fields when name is empty.
+type usageGroup struct {
+	name string
+	rows [][]string
+}
+
+// usageCommand is a command and the rows of its own fields.
+type usageCommand struct {
+	path  string
+	name  string
+	usage string
+	depth int
+	rows  [][]string
+}
+
+// usage collects the metadata of the fields of the last parse.
+func (c *config[C]) usage() usage {
+	c.mu.Lock()
+	opts := c.usageOpts
+	c.mu.Unlock()
+
+	if opts.Name == "" {
+		opts.Name = path.Base(os.Args[0])
+	}
+	if opts.Synopsis == "" {
+		opts.Synopsis = defaultSynopsis
+	}
+	if opts.Version == "" {
+		opts.Version = c.version()
+	}
+
+	// sort a copy, the fields may be of a published snapshot.
+	fields := slices.Clone(c.lastAttempt())
+	fields = slices.DeleteFunc(fields, func(f flat.Field) bool {
+		_, hidden := f.Tag(hiddenTag)
+		return hidden
 	})
 
-	for _, f := range c.fields {
+	setUsageMeta(fields)
 
-		values := make([]string, len(headers))
+	sort.SliceStable(fields, func(i, j int) bool {
+		return flag.IsCommand(fields[j]) // move command to last.
+	})
+
+	tree := c.commandTree()
+	scopes := commandScopes(tree, nil)
+
+	var extra []string
+	if opts.Values {
+		extra = []string{valueColumn, sourceColumn}
+	}
+
+	u := usage{
+		UsageOptions: opts,
+		headers:      getHeaders(fields, extra...),
+		fields:       usageFields(fields, scopes, opts.Values),
+		files:        file.FileNames(c.plugins),
+	}
+
+	if len(opts.Columns) > 0 {
+		u.headers = append([]string{"field"}, opts.Columns...)
+	}
+
+	rows := usageRows(fields, u.headers, func(name string) bool {
+		return scopes[name] == ""
+	})
+	u.groups = groupRows(rows, opts.Group)
+
+	walkCommands(tree, nil, func(path string, cmd plugins.Command) {
+		u.commands = append(u.commands, usageCommand{
+			path:  path,
+			name:  cmd.Name,
+			usage: cmd.Usage,
+			depth: strings.Count(path, " "),
+			rows: usageRows(fields, u.headers, func(name string) bool {
+				return scopes[name] == path
+			}),
+		})
+	})
+
+	return u
+}
+
+// usageRows returns a row for each of the fields that match.
+func usageRows(fields flat.Fields, headers []string, match func(name string) bool) [][]string {
+	var rows [][]string
+
+	for _, f := range fields {
 		name, _ := f.Name("")
+		if !match(name) {
+			continue
+		}
+
+		values := make([]string, len(headers))
 		values[0] = name
 		for i, header := range headers[1:] {
-			value := f.Meta()[header]
-			values[i+1] = value
+			values[i+1] = usageCell(f, header)
 		}
 
-		_, _ = fmt.Fprintln(w, strings.Join(values, "\t"))
+		rows = append(rows, values)
+	}
+
+	return rows
+}
 
+// usageCell returns the metadata of the field for the column.
+func usageCell(f flat.Field, header string) string {
+	switch header {
+	case valueColumn:
+		return displayValue(f, formatValue(f.Interface()))
+	case sourceColumn:
+		return usageSource(f)
 	}
 
-	files := file.FileNames(c.plugins)
+	return f.Meta()[header]
+}
 
-	if len(files) > 0 {
-		_, _ = fmt.Fprintf(w, "\nConfiguration Files:\n")
-		for _, fp := range files {
-			_, _ = fmt.Fprintf(w, "\t%s\n", fp)
+// usageSource returns the plugin and key that last set the field.
+func usageSource(f flat.Field) string {
+	sources := f.Sources()
+	if len(sources) == 0 {
+		return ""
+	}
+
+	src := sources[len(sources)-1]
+	if src.Key == "" {
+		return src.Plugin
+	}
+	return src.Plugin + " " + src.Key
+}
+
+// groupRows groups the rows by the top level struct of their field,
+// the rows of fields that are not in a struct come first.
+func groupRows(rows [][]string, group bool) []usageGroup {
+	if !group {
+		return []usageGroup{{rows: rows}}
+	}
+
+	groups := []usageGroup{{}}
+	index := map[string]int{"": 0}
+
+	for _, row := range rows {
+		name, _, nested := strings.Cut(row[0], ".")
+		if !nested {
+			name = ""
 		}
 
+		i, ok := index[name]
+		if !ok {
+			i = len(groups)
+			index[name] = i
+			groups = append(groups, usageGroup{name: name})
+		}
+
+		groups[i].rows = append(groups[i].rows, row)
+	}
+
+	return groups
+}
+
+// usageWidth returns the width the text written to out is wrapped
+// to, or 0.
+func usageWidth(opts UsageOptions, out io.Writer) int {
+	if opts.Width != 0 {
+		return max(opts.Width, 0)
+	}
+
+	// shells don't export COLUMNS, but it is
+	// the way to override the terminal size.
+	width, err := strconv.Atoi(os.Getenv("COLUMNS"))
+	if err == nil {
+		return max(width, 0)
 	}
 
-	err := w.Flush()
+	return terminalWidth(out)
+}
+
+// terminalWidth returns the width of the terminal w writes to, or 0
+// if it is not a terminal, like a file or a pipe.
+func terminalWidth(w io.Writer) int {
+	f, ok := w.(interface{ Fd() uintptr })
+	if !ok || !term.IsTerminal(int(f.Fd())) {
+		return 0
+	}
+
+	width, _, err := term.GetSize(int(f.Fd()))
 	if err != nil {
-		// we are asked for usage which means it is interactive use
-		// and so panicking is acceptable.
-		panic(err)
+		return 0
+	}
+
+	return width
+}
+
+// wrapText breaks the text into lines of up to width, at spaces.
+func wrapText(text string, width int) []string {
+	words := strings.Fields(text)
+	if width <= 0 || len(words) == 0 {
+		return []string{text}
+	}
+
+	var lines []string
+	line := words[0]
+
+	for _, word := range words[1:] {
+		if len(line)+1+len(word) > width {
+			lines = append(lines, line)
+			line = word
+			continue
+		}
+		line += " " + word
+	}
+
+	return append(lines, line)
+}
+
+// wrapRows wraps the last column of the rows to what is left of the
+// width, the lines after the first are rows of their own.
+func wrapRows(u usage, width int) usage {
+	if width == 0 || len(u.headers) < 2 {
+		return u
+	}
+
+	last := len(u.headers) - 1
+
+	// the width of the columns before the last, as tabwriter pads them.
+	offset := 0
+	for i := 0; i < last; i++ {
+		column := max(len(u.headers[i]), 5)
+		for _, group := range u.groups {
+			column = max(column, len(group.name)+1)
+			for _, row := range group.rows {
+				column = max(column, len(row[i]))
+			}
+		}
+		for _, cmd := range u.commands {
+			for _, row := range cmd.rows {
+				column = max(column, len(row[i]))
+			}
+		}
+		offset += column + usagePadding
+	}
+
+	// too narrow to be of use, wrap to the minimum.
+	available := max(width-offset, 20)
+
+	wrap := func(rows [][]string) [][]string {
+		var wrapped [][]string
+		for _, row := range rows {
+			lines := wrapText(row[last], available)
+
+			first := slices.Clone(row)
+			first[last] = lines[0]
+			wrapped = append(wrapped, first)
+
+			for _, line := range lines[1:] {
+				next := make([]string, len(row))
+				next[last] = line
+				wrapped = append(wrapped, next)
+			}
+		}
+		return wrapped
+	}
+
+	groups := make([]usageGroup, len(u.groups))
+	for i, group := range u.groups {
+		groups[i] = usageGroup{name: group.name, rows: wrap(group.rows)}
+	}
+	u.groups = groups
+
+	commands := make([]usageCommand, len(u.commands))
+	for i, cmd := range u.commands {
+		cmd.rows = wrap(cmd.rows)
+		commands[i] = cmd
+	}
+	u.commands = commands
+
+	return u
+}
+
+const usagePadding = 4
+
+func writeUsageText(out io.Writer, u usage) error {
+	width := usageWidth(u.UsageOptions, out)
+	u = wrapRows(u, width)
+
+	heading := func(title string) string { return title }
+	if u.Color && os.Getenv("NO_COLOR") == "" {
+		heading = func(title string) string { return "\x1b[1m" + title + "\x1b[0m" }
+	}
+
+	w := tabwriter.NewWriter(out, 0, 0, usagePadding, ' ', 0)
+
+	if u.Version != "" {
+		_, _ = fmt.Fprintf(w, "%s %s\n\n", u.Name, u.Version)
+	}
+
+	if u.Description != "" {
+		for _, line := range wrapText(u.Description, width) {
+			_, _ = fmt.Fprintln(w, line)
+		}
+		_, _ = fmt.Fprintln(w)
+	}
+
+	_, _ = fmt.Fprintf(w, "%s\n\t%s %s\n", heading("Usage:"), u.Name, u.Synopsis)
+
+	if len(u.Examples) > 0 {
+		_, _ = fmt.Fprintf(w, "\n%s\n", heading("Examples:"))
+		for _, example := range u.Examples {
+			_, _ = fmt.Fprintf(w, "\t%s\n", example)
+		}
+	}
+
+	_, _ = fmt.Fprintf(w, "\n%s\n", heading("Configurations:"))
+	_, _ = fmt.Fprintln(w, strings.ToUpper(strings.Join(u.headers, "\t")))
+
+	dashes := make([]string, len(u.headers))
+	for i, f := range u.headers {
+		n := max(len(f), 5)
+		dashes[i] = strings.Repeat("-", n)
+	}
+	_, _ = fmt.Fprintln(w, strings.Join(dashes, "\t"))
+
+	// the group headings have the columns of the rows
+	// so that all of the groups are aligned together.
+	empty := strings.Repeat("\t", len(u.headers)-1)
+	for i, group := range u.groups {
+		if group.name != "" {
+			if i > 1 || len(u.groups[0].rows) > 0 {
+				_, _ = fmt.Fprintln(w, empty)
+			}
+			_, _ = fmt.Fprintf(w, "%s:%s\n", group.name, empty)
+		}
+		writeUsageRows(w, group.rows)
+	}
+
+	if len(u.commands) > 0 {
+		_, _ = fmt.Fprintf(w, "\n%s\n", heading("Commands:"))
+		for _, cmd := range u.commands {
+			_, _ = fmt.Fprintf(w, "\t%s%s\t%s\n", strings.Repeat("  ", cmd.depth), cmd.name, cmd.usage)
+		}
+
+		for _, cmd := range u.commands {
+			if len(cmd.rows) == 0 {
+				continue
+			}
+
+			_, _ = fmt.Fprintf(w, "\n%s\n", heading("Command "+cmd.path+":"))
+			writeUsageRows(w, cmd.rows)
+		}
+	}
+
+	if len(u.files) > 0 {
+		_, _ = fmt.Fprintf(w, "\n%s\n", heading("Configuration Files:"))
+		for _, fp := range u.files {
+			_, _ = fmt.Fprintf(w, "\t%s\n", fp)
+		}
+
+	}
+
+	return w.Flush()
+}
+
+func writeUsageRows(w io.Writer, rows [][]string) {
+	for _, row := range rows {
+		_, _ = fmt.Fprintln(w, strings.Join(row, "\t"))
+	}
+}
+
+// walkCommands calls fn for every command with its path, parents first.
+func walkCommands(tree []plugins.Command, path []string, fn func(path string, cmd plugins.Command)) {
+	for _, cmd := range tree {
+		path := append(path[:len(path):len(path)], cmd.Name)
+		fn(strings.Join(path, " "), cmd)
+		walkCommands(cmd.Commands, path, fn)
 	}
 }
 
 func setUsageMeta(fs flat.Fields) {
 	for _, f := range fs {
+		if rules, ok := f.Tag(validateTag); ok && rules != "" {
+			f.Meta()[validateTag] = rules
+		}
+
 		usage, ok := f.Tag(usageTag)
 		if !ok {
 			continue
@@ -90,9 +545,13 @@ func setUsageMeta(fs flat.Fields) {
 	}
 }
 
-func getHeaders(fs flat.Fields) []string {
+func getHeaders(fs flat.Fields, extra ...string) []string {
 	tagMap := map[string]struct{}{}
 
+	for _, key := range extra {
+		tagMap[key] = struct{}{}
+	}
+
 	for _, f := range fs {
 		for key := range f.Meta() {
 			tagMap[key] = struct{}{}
@@ -108,10 +567,14 @@ func getHeaders(fs flat.Fields) []string {
 	}
 
 	weights := map[string]int{
-		"field": 1,
-		"usage": 99,
-		"flag":  3,
-		"env":   4,
+		"field":    1,
+		"value":    95,
+		"source":   96,
+		"validate": 97,
+		"usage":    99,
+		"flag":     3,
+		"env":      4,
+		"env_file": 5,
 	}
 
 	weight := func(tags []string, i int) int {
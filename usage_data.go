@@ -0,0 +1,198 @@
+package uconfig
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"sort"
+
+	"github.com/omeid/uconfig/flat"
+)
+
+// usageData is the usage as data, for the JSON and YAML formats.
+type usageData struct {
+	Name        string             `json:"name"`
+	Version     string             `json:"version,omitempty"`
+	Description string             `json:"description,omitempty"`
+	Fields      []usageField       `json:"fields"`
+	Commands    []usageDataCommand `json:"commands,omitempty"`
+	Files       []string           `json:"files,omitempty"`
+}
+
+// usageField is a field and the names it is known by.
+type usageField struct {
+	Name     string            `json:"name"`
+	Type     string            `json:"type,omitempty"`
+	Command  string            `json:"command,omitempty"`
+	Default  string            `json:"default,omitempty"`
+	Flag     string            `json:"flag,omitempty"`
+	Env      string            `json:"env,omitempty"`
+	Secret   string            `json:"secret,omitempty"`
+	Required bool              `json:"required,omitempty"`
+	Validate string            `json:"validate,omitempty"`
+	Usage    string            `json:"usage,omitempty"`
+	Value    string            `json:"value,omitempty"`
+	Source   string            `json:"source,omitempty"`
+	Meta     map[string]string `json:"meta,omitempty"`
+}
+
+type usageDataCommand struct {
+	Name  string `json:"name"`
+	Usage string `json:"usage,omitempty"`
+}
+
+// usageFields describes the fields, the metadata of
+// plugins other than the built-in ones goes in Meta.
+func usageFields(fields flat.Fields, scopes map[string]string, values bool) []usageField {
+	described := make([]usageField, 0, len(fields))
+
+	for _, f := range fields {
+		name, _ := f.Name("")
+		meta := f.Meta()
+
+		uf := usageField{
+			Name:     name,
+			Command:  scopes[name],
+			Default:  meta["default"],
+			Flag:     meta["flag"],
+			Env:      meta["env"],
+			Secret:   meta["secret"],
+			Validate: meta[validateTag],
+			Usage:    meta[usageTag],
+		}
+
+		// unexported fields have no value to tell the type from.
+		if value := f.Interface(); value != nil {
+			uf.Type = reflect.TypeOf(value).String()
+		}
+
+		_, uf.Required = f.Tag(requiredTag)
+
+		if values {
+			uf.Value = usageCell(f, valueColumn)
+			uf.Source = usageCell(f, sourceColumn)
+		}
+
+		for key, value := range meta {
+			switch key {
+			case "default", "flag", "env", "secret", validateTag, usageTag:
+				continue
+			}
+
+			if uf.Meta == nil {
+				uf.Meta = map[string]string{}
+			}
+			uf.Meta[key] = value
+		}
+
+		described = append(described, uf)
+	}
+
+	return described
+}
+
+func (u usage) data() usageData {
+	data := usageData{
+		Name:        u.Name,
+		Version:     u.Version,
+		Description: u.Description,
+		Fields:      u.fields,
+		Files:       u.files,
+	}
+
+	for _, cmd := range u.commands {
+		data.Commands = append(data.Commands, usageDataCommand{Name: cmd.path, Usage: cmd.usage})
+	}
+
+	return data
+}
+
+func writeUsageJSON(w io.Writer, u usage) error {
+	enc := json.NewEncoder(w)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+
+	return enc.Encode(u.data())
+}
+
+func writeUsageYAML(out io.Writer, u usage) error {
+	w := bufio.NewWriter(out)
+	data := u.data()
+
+	writeYAMLPairs(w, "", [][2]string{
+		{"name", data.Name},
+		{"version", data.Version},
+		{"description", data.Description},
+	})
+
+	if len(data.Fields) == 0 {
+		_, _ = fmt.Fprintln(w, "fields: []")
+	} else {
+		_, _ = fmt.Fprintln(w, "fields:")
+	}
+
+	for _, f := range data.Fields {
+		_, _ = fmt.Fprintf(w, "  - name: %s\n", yamlString(f.Name))
+		writeYAMLPairs(w, "    ", [][2]string{
+			{"type", f.Type},
+			{"command", f.Command},
+			{"default", f.Default},
+			{"flag", f.Flag},
+			{"env", f.Env},
+			{"secret", f.Secret},
+		})
+		if f.Required {
+			_, _ = fmt.Fprintln(w, "    required: true")
+		}
+		writeYAMLPairs(w, "    ", [][2]string{
+			{"validate", f.Validate},
+			{"usage", f.Usage},
+			{"value", f.Value},
+			{"source", f.Source},
+		})
+
+		if len(f.Meta) == 0 {
+			continue
+		}
+
+		keys := make([]string, 0, len(f.Meta))
+		for key := range f.Meta {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+
+		_, _ = fmt.Fprintln(w, "    meta:")
+		for _, key := range keys {
+			_, _ = fmt.Fprintf(w, "      %s: %s\n", yamlString(key), yamlString(f.Meta[key]))
+		}
+	}
+
+	if len(data.Commands) > 0 {
+		_, _ = fmt.Fprintln(w, "commands:")
+		for _, cmd := range data.Commands {
+			_, _ = fmt.Fprintf(w, "  - name: %s\n", yamlString(cmd.Name))
+			writeYAMLPairs(w, "    ", [][2]string{{"usage", cmd.Usage}})
+		}
+	}
+
+	if len(data.Files) > 0 {
+		_, _ = fmt.Fprintln(w, "files:")
+		for _, fp := range data.Files {
+			_, _ = fmt.Fprintf(w, "  - %s\n", yamlString(fp))
+		}
+	}
+
+	return w.Flush()
+}
+
+// writeYAMLPairs writes the pairs that have a value as strings.
+func writeYAMLPairs(w io.Writer, indent string, pairs [][2]string) {
+	for _, pair := range pairs {
+		key, value := pair[0], pair[1]
+		if value != "" {
+			_, _ = fmt.Fprintf(w, "%s%s: %s\n", indent, key, yamlString(value))
+		}
+	}
+}
@@ -0,0 +1,207 @@
+package uconfig
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// usageTitle returns the header as a title, e.g. env becomes Env.
+func usageTitle(header string) string {
+	if header == "" {
+		return header
+	}
+	return strings.ToUpper(header[:1]) + header[1:]
+}
+
+// usageLine joins the lines of a value, table cells and
+// roff requests are a single line.
+func usageLine(value string) string {
+	return strings.Join(strings.Fields(value), " ")
+}
+
+// roffEscape escapes the value for roff, and so that a line
+// starting with it is not taken for a request.
+func roffEscape(value string) string {
+	value = strings.NewReplacer(`\`, `\e`, "-", `\-`).Replace(usageLine(value))
+	if strings.HasPrefix(value, ".") || strings.HasPrefix(value, "'") {
+		value = `\&` + value
+	}
+	return value
+}
+
+// writeUsageMan writes the usage as a man page, each field is an
+// entry named after it with its usage and the other columns below.
+func writeUsageMan(out io.Writer, u usage) error {
+	w := bufio.NewWriter(out)
+
+	name := roffEscape(u.Name)
+	_, _ = fmt.Fprintf(w, ".TH \"%s\" \"1\"", strings.ToUpper(name))
+	if u.Version != "" {
+		_, _ = fmt.Fprintf(w, " \"\" \"%s %s\"", name, roffEscape(u.Version))
+	}
+	_, _ = fmt.Fprintf(w, "\n.SH NAME\n%s\n", name)
+	_, _ = fmt.Fprintf(w, ".SH SYNOPSIS\n.B %s\n%s\n", name, roffEscape(u.Synopsis))
+
+	if u.Description != "" {
+		_, _ = fmt.Fprintf(w, ".SH DESCRIPTION\n%s\n", roffEscape(u.Description))
+	}
+
+	_, _ = fmt.Fprintf(w, ".SH CONFIGURATIONS\n")
+	for _, group := range u.groups {
+		if group.name != "" {
+			_, _ = fmt.Fprintf(w, ".SS \"%s\"\n", roffEscape(group.name))
+		}
+		writeManRows(w, u.headers, group.rows)
+	}
+
+	if len(u.commands) > 0 {
+		_, _ = fmt.Fprintf(w, ".SH COMMANDS\n")
+		for _, cmd := range u.commands {
+			_, _ = fmt.Fprintf(w, ".TP\n.B %s\n", roffEscape(cmd.path))
+			if cmd.usage != "" {
+				_, _ = fmt.Fprintln(w, roffEscape(cmd.usage))
+			}
+		}
+
+		for _, cmd := range u.commands {
+			if len(cmd.rows) == 0 {
+				continue
+			}
+
+			_, _ = fmt.Fprintf(w, ".SS \"Command %s\"\n", roffEscape(cmd.path))
+			writeManRows(w, u.headers, cmd.rows)
+		}
+	}
+
+	if len(u.Examples) > 0 {
+		_, _ = fmt.Fprintf(w, ".SH EXAMPLES\n.nf\n")
+		for _, example := range u.Examples {
+			_, _ = fmt.Fprintln(w, roffEscape(example))
+		}
+		_, _ = fmt.Fprintf(w, ".fi\n")
+	}
+
+	if len(u.files) > 0 {
+		_, _ = fmt.Fprintf(w, ".SH FILES\n.nf\n")
+		for _, fp := range u.files {
+			_, _ = fmt.Fprintln(w, roffEscape(fp))
+		}
+		_, _ = fmt.Fprintf(w, ".fi\n")
+	}
+
+	return w.Flush()
+}
+
+func writeManRows(w io.Writer, headers []string, rows [][]string) {
+	for _, row := range rows {
+		_, _ = fmt.Fprintf(w, ".TP\n.B %s\n", roffEscape(row[0]))
+
+		var lines []string
+		for i, header := range headers[1:] {
+			value := row[i+1]
+			if value == "" {
+				continue
+			}
+
+			if header == usageTag {
+				// the description comes first.
+				lines = append([]string{roffEscape(value)}, lines...)
+				continue
+			}
+
+			lines = append(lines, usageTitle(header)+": "+roffEscape(value))
+		}
+
+		_, _ = fmt.Fprintln(w, strings.Join(lines, "\n.br\n"))
+	}
+}
+
+// markdownCell escapes the value for a table cell, names and
+// values are code, usage and validation rules are text.
+func markdownCell(header string, value string) string {
+	value = strings.ReplaceAll(usageLine(value), "|", `\|`)
+	if value == "" || header == usageTag || header == validateTag {
+		return value
+	}
+	return "`" + value + "`"
+}
+
+// writeUsageMarkdown writes the usage as a Markdown document,
+// the fields are tables with the same columns as the text usage.
+func writeUsageMarkdown(out io.Writer, u usage) error {
+	w := bufio.NewWriter(out)
+
+	_, _ = fmt.Fprintf(w, "# %s\n\n", u.Name)
+
+	if u.Version != "" {
+		_, _ = fmt.Fprintf(w, "Version %s\n\n", u.Version)
+	}
+
+	if u.Description != "" {
+		_, _ = fmt.Fprintf(w, "%s\n\n", u.Description)
+	}
+
+	_, _ = fmt.Fprintf(w, "## Usage\n\n```\n%s %s\n```\n", u.Name, u.Synopsis)
+
+	if len(u.Examples) > 0 {
+		_, _ = fmt.Fprintf(w, "\n## Examples\n\n```\n%s\n```\n", strings.Join(u.Examples, "\n"))
+	}
+
+	_, _ = fmt.Fprintf(w, "\n## Configurations\n")
+	for _, group := range u.groups {
+		if group.name != "" {
+			_, _ = fmt.Fprintf(w, "\n### %s\n", group.name)
+		} else if len(group.rows) == 0 && len(u.groups) > 1 {
+			continue
+		}
+		_, _ = fmt.Fprintln(w)
+		writeMarkdownRows(w, u.headers, group.rows)
+	}
+
+	if len(u.commands) > 0 {
+		_, _ = fmt.Fprintf(w, "\n## Commands\n\n| Command | Usage |\n| --- | --- |\n")
+		for _, cmd := range u.commands {
+			_, _ = fmt.Fprintf(w, "| %s | %s |\n", markdownCell("", cmd.path), markdownCell(usageTag, cmd.usage))
+		}
+
+		for _, cmd := range u.commands {
+			if len(cmd.rows) == 0 {
+				continue
+			}
+
+			_, _ = fmt.Fprintf(w, "\n### Command `%s`\n\n", cmd.path)
+			writeMarkdownRows(w, u.headers, cmd.rows)
+		}
+	}
+
+	if len(u.files) > 0 {
+		_, _ = fmt.Fprintf(w, "\n## Configuration Files\n\n")
+		for _, fp := range u.files {
+			_, _ = fmt.Fprintf(w, "- `%s`\n", fp)
+		}
+	}
+
+	return w.Flush()
+}
+
+func writeMarkdownRows(w io.Writer, headers []string, rows [][]string) {
+	titles := make([]string, len(headers))
+	dashes := make([]string, len(headers))
+	for i, header := range headers {
+		titles[i] = usageTitle(header)
+		dashes[i] = "---"
+	}
+
+	_, _ = fmt.Fprintf(w, "| %s |\n", strings.Join(titles, " | "))
+	_, _ = fmt.Fprintf(w, "| %s |\n", strings.Join(dashes, " | "))
+
+	for _, row := range rows {
+		cells := make([]string, len(row))
+		for i, value := range row {
+			cells[i] = markdownCell(headers[i], value)
+		}
+		_, _ = fmt.Fprintf(w, "| %s |\n", strings.Join(cells, " | "))
+	}
+}
@@ -3,6 +3,8 @@ package uconfig_test
 import (
 	"bytes"
 	"encoding/json"
+	"os"
+	"strings"
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
@@ -10,7 +12,10 @@ import (
 	"github.com/omeid/uconfig/flat"
 	"github.com/omeid/uconfig/internal/f"
 	"github.com/omeid/uconfig/plugins"
+	"github.com/omeid/uconfig/plugins/defaults"
+	"github.com/omeid/uconfig/plugins/env"
 	"github.com/omeid/uconfig/plugins/file"
+	"github.com/omeid/uconfig/plugins/flag"
 	"github.com/omeid/uconfig/plugins/secret"
 )
 
@@ -92,3 +97,350 @@ func TestUsage(t *testing.T) {
 		t.Error(diff)
 	}
 }
+
+const expectedUsageMan = `.TH "UCONFIG.TEST" "1"
+.SH NAME
+uconfig.test
+.SH SYNOPSIS
+.B uconfig.test
+[flags] [command]
+.SH CONFIGURATIONS
+.TP
+.B Addr
+address to listen on, e.g. host|port
+.br
+Flag: \-addr
+.br
+Env: ADDR
+.br
+Default: :8080
+.TP
+.B Debug
+Flag: \-debug
+.br
+Env: DEBUG
+`
+
+const expectedUsageMarkdown = "# uconfig.test\n" +
+	"\n" +
+	"## Usage\n" +
+	"\n" +
+	"```\n" +
+	"uconfig.test [flags] [command]\n" +
+	"```\n" +
+	"\n" +
+	"## Configurations\n" +
+	"\n" +
+	"| Field | Flag | Env | Default | Usage |\n" +
+	"| --- | --- | --- | --- | --- |\n" +
+	"| `Addr` | `-addr` | `ADDR` | `:8080` | address to listen on, e.g. host\\|port |\n" +
+	"| `Debug` | `-debug` | `DEBUG` |  |  |\n"
+
+func TestWriteUsage(t *testing.T) {
+	type Config struct {
+		Addr  string `default:":8080" usage:"address to listen on, e.g. host|port"`
+		Debug bool
+	}
+
+	conf := uconfig.New[Config](defaults.New(), env.New(), flag.New("testing", flag.ContinueOnError, nil))
+	_, err := conf.Parse()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		format uconfig.UsageFormat
+		expect string
+	}{
+		{uconfig.UsageMan, expectedUsageMan},
+		{uconfig.UsageMarkdown, expectedUsageMarkdown},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.format), func(t *testing.T) {
+			var out bytes.Buffer
+			err := conf.WriteUsage(&out, tt.format)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if diff := cmp.Diff(tt.expect, out.String()); diff != "" {
+				t.Error(diff)
+			}
+		})
+	}
+
+	err = conf.WriteUsage(&bytes.Buffer{}, "html")
+	if err == nil {
+		t.Error("expected an error for an unknown usage format")
+	}
+}
+
+const expectedUsageOptions = `app 1.2.0
+
+app does things.
+
+Usage:
+    app [flags] [command]
+
+Examples:
+    app -debug
+
+Configurations:
+FIELD            FLAG              ENV              DEFAULT      USAGE
+-----            -----             -----            -------      -----
+Debug            -debug            DEBUG                         log everything that
+                                                                 happens, which is a
+                                                                 lot of things to log
+                                                                 
+Redis:                                                           
+Redis.Address    -redis-address    REDIS_ADDRESS    localhost    the address
+Redis.Port       -redis-port       REDIS_PORT                    
+`
+
+func TestUsageOptions(t *testing.T) {
+	type Config struct {
+		Debug bool   `usage:"log everything that happens, which is a lot of things to log"`
+		Token string `hidden:""`
+		Redis struct {
+			Address string `default:"localhost" usage:"the address"`
+			Port    int
+		}
+	}
+
+	conf := uconfig.New[Config](defaults.New(), env.New(), flag.New("testing", flag.ContinueOnError, nil))
+	_, err := conf.Parse()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	opts := uconfig.UsageOptions{
+		Name:        "app",
+		Version:     "1.2.0",
+		Description: "app does things.",
+		Examples:    []string{"app -debug"},
+		Group:       true,
+		Width:       60,
+		Color:       true,
+	}
+	conf.SetUsageOptions(opts)
+
+	t.Setenv("NO_COLOR", "1")
+
+	var out bytes.Buffer
+	err = conf.WriteUsage(&out, uconfig.UsageText)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if diff := cmp.Diff(expectedUsageOptions, out.String()); diff != "" {
+		t.Error(diff)
+	}
+
+	t.Setenv("NO_COLOR", "")
+
+	out.Reset()
+	opts.Columns = []string{"env"}
+	conf.SetUsageOptions(opts)
+
+	err = conf.WriteUsage(&out, uconfig.UsageText)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, expect := range []string{"\x1b[1mUsage:\x1b[0m\n", "FIELD            ENV\n"} {
+		if !strings.Contains(out.String(), expect) {
+			t.Errorf("expected %q in:\n%s", expect, out.String())
+		}
+	}
+}
+
+const expectedUsageJSON = `{
+  "name": "uconfig.test",
+  "fields": [
+    {
+      "name": "Addr",
+      "type": "string",
+      "default": ":8080",
+      "flag": "-addr",
+      "env": "ADDR",
+      "required": true,
+      "validate": "hostport",
+      "usage": "address to listen on",
+      "meta": {
+        "goodplugin": "Addr"
+      }
+    },
+    {
+      "name": "Token",
+      "type": "string",
+      "flag": "-token",
+      "env": "TOKEN",
+      "secret": "TOKEN",
+      "meta": {
+        "goodplugin": "Token"
+      }
+    },
+    {
+      "name": "Level",
+      "type": "string",
+      "flag": "-level",
+      "env": "LEVEL",
+      "usage": "yes",
+      "meta": {
+        "goodplugin": "Level"
+      }
+    }
+  ]
+}
+`
+
+const expectedUsageYAML = `name: uconfig.test
+fields:
+  - name: Addr
+    type: string
+    default: ":8080"
+    flag: "-addr"
+    env: ADDR
+    required: true
+    validate: hostport
+    usage: address to listen on
+    meta:
+      goodplugin: Addr
+  - name: Token
+    type: string
+    flag: "-token"
+    env: TOKEN
+    secret: TOKEN
+    meta:
+      goodplugin: Token
+  - name: Level
+    type: string
+    flag: "-level"
+    env: LEVEL
+    usage: "yes"
+    meta:
+      goodplugin: Level
+`
+
+func TestWriteUsageData(t *testing.T) {
+	type Config struct {
+		Addr  string `default:":8080" usage:"address to listen on" required:"" validate:"hostport"`
+		Token string `secret:""`
+		Level string `usage:"yes"`
+	}
+
+	secretProvider := func(name string) (string, error) { return "top secret token", nil }
+
+	conf := uconfig.New[Config](
+		defaults.New(),
+		env.New(),
+		secret.New(secretProvider),
+		flag.New("testing", flag.ContinueOnError, nil),
+		&UselessPluginVisitor{},
+	)
+
+	_, err := conf.Parse()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		format uconfig.UsageFormat
+		expect string
+	}{
+		{uconfig.UsageJSON, expectedUsageJSON},
+		{uconfig.UsageYAML, expectedUsageYAML},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.format), func(t *testing.T) {
+			var out bytes.Buffer
+			err := conf.WriteUsage(&out, tt.format)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if diff := cmp.Diff(tt.expect, out.String()); diff != "" {
+				t.Error(diff)
+			}
+		})
+	}
+}
+
+const expectedUsageValues = `Usage:
+    uconfig.test [flags] [command]
+
+Configurations:
+FIELD    ENV      VALUE     SOURCE
+-----    -----    -----     ------
+Host     HOST     db        env HOST
+Port     PORT     5432      default
+Token    TOKEN    ******    secret TOKEN
+Debug    DEBUG    false     
+`
+
+func TestUsageValues(t *testing.T) {
+	type Config struct {
+		Host  string `default:"localhost"`
+		Port  int    `default:"5432"`
+		Token string `secret:""`
+		Debug bool
+	}
+
+	t.Setenv("HOST", "db")
+
+	secretProvider := func(name string) (string, error) { return "top secret token", nil }
+
+	conf := uconfig.New[Config](defaults.New(), env.New(), secret.New(secretProvider))
+	_, err := conf.Parse()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	conf.SetUsageOptions(uconfig.UsageOptions{
+		Columns: []string{"env", "value", "source"},
+		Values:  true,
+	})
+
+	var out bytes.Buffer
+	err = conf.WriteUsage(&out, uconfig.UsageText)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if diff := cmp.Diff(expectedUsageValues, out.String()); diff != "" {
+		t.Error(diff)
+	}
+}
+
+func TestUsageWidth(t *testing.T) {
+	type Config struct {
+		Debug bool `usage:"log everything that happens, which is a lot of things to log"`
+	}
+
+	conf := uconfig.New[Config](flag.New("testing", flag.ContinueOnError, nil))
+	_, err := conf.Parse()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	lines := func() []string {
+		var out bytes.Buffer
+		err := conf.WriteUsage(&out, uconfig.UsageText)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return strings.Split(strings.TrimSpace(out.String()), "\n")
+	}
+
+	// COLUMNS is not set and a buffer is not a terminal.
+	t.Setenv("COLUMNS", "")
+	os.Unsetenv("COLUMNS")
+	unwrapped := lines()
+
+	t.Setenv("COLUMNS", "40")
+	if wrapped := lines(); len(wrapped) <= len(unwrapped) {
+		t.Errorf("expected the usage to be wrapped to COLUMNS, got:\n%s", strings.Join(wrapped, "\n"))
+	}
+}
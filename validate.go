@@ -0,0 +1,236 @@
+package uconfig
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/omeid/uconfig/flat"
+	"github.com/omeid/uconfig/plugins"
+)
+
+const validateTag = "validate"
+
+func init() {
+	plugins.RegisterTag(validateTag)
+}
+
+// validate checks every field against the rules in its validate tag
+// and reports all the violations at once.
+//
+// The rules are separated by comma and are one of:
+//
+//	min=N, max=N  bounds for numbers and durations, or the length of
+//	              strings, slices and maps.
+//	oneof=a|b|c   the value must be one of the options.
+//	regexp=expr   the value must match expr, as expr may contain
+//	              commas, it must be the last rule.
+//	nonzero       the value must be set to something other than zero.
+//	url           the value must be an absolute url.
+//	hostport      the value must be a host:port pair.
+//
+// With the exception of nonzero, min and max, rules are not checked
+// against zero values so that optional fields can be left unset.
+func validate(fs flat.Fields, failed map[string]bool) error {
+	var errs error
+
+	for _, f := range fs {
+		rules, ok := f.Tag(validateTag)
+		if !ok || rules == "" {
+			continue
+		}
+
+		name, _ := f.Name("")
+		if failed[name] {
+			continue
+		}
+
+		for _, err := range validateField(f, rules) {
+			errs = errors.Join(errs, &FieldError{
+				Field:  name,
+				Plugin: validateTag,
+				Key:    strings.Join(fieldNames(f), ", "),
+				Value:  formatValue(f.Interface()),
+				Err:    err,
+			})
+		}
+	}
+
+	return errs
+}
+
+// fieldNames returns the flag and env names the field can be set with.
+func fieldNames(f flat.Field) []string {
+	var names []string
+	for _, key := range []string{"flag", "env"} {
+		if name := f.Meta()[key]; name != "" && name != "-" {
+			names = append(names, name)
+		}
+	}
+
+	return names
+}
+
+func validateField(f flat.Field, rules string) []error {
+	var errs []error
+
+	value := reflect.ValueOf(f.Interface())
+	for value.Kind() == reflect.Pointer && !value.IsNil() {
+		value = value.Elem()
+	}
+
+	for rules != "" {
+		var rule string
+		if strings.HasPrefix(rules, "regexp=") {
+			rule, rules = rules, ""
+		} else {
+			rule, rules, _ = strings.Cut(rules, ",")
+		}
+
+		rule = strings.TrimSpace(rule)
+		if rule == "" {
+			continue
+		}
+
+		name, arg, _ := strings.Cut(rule, "=")
+
+		err := validateRule(value, name, arg)
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return errs
+}
+
+func validateRule(value reflect.Value, rule string, arg string) error {
+	zero := !value.IsValid() || value.IsZero()
+
+	switch rule {
+	case "nonzero":
+		if zero {
+			return errors.New("must be set")
+		}
+		return nil
+
+	case "min", "max":
+		return validateBound(value, rule, arg)
+	}
+
+	if zero {
+		return nil
+	}
+
+	str := formatValue(value.Interface())
+
+	switch rule {
+	case "oneof":
+		options := strings.Split(arg, "|")
+		for _, option := range options {
+			if str == option {
+				return nil
+			}
+		}
+		return fmt.Errorf("must be one of %s, got %q", strings.Join(options, ", "), str)
+
+	case "regexp":
+		re, err := regexp.Compile(arg)
+		if err != nil {
+			return fmt.Errorf("bad regexp rule: %w", err)
+		}
+		if !re.MatchString(str) {
+			return fmt.Errorf("must match %s, got %q", arg, str)
+		}
+
+	case "url":
+		u, err := url.Parse(str)
+		if err != nil {
+			return fmt.Errorf("must be a url: %w", err)
+		}
+		if u.Scheme == "" || (u.Host == "" && u.Opaque == "" && u.Path == "") {
+			return fmt.Errorf("must be an absolute url, got %q", str)
+		}
+
+	case "hostport":
+		_, port, err := net.SplitHostPort(str)
+		if err != nil {
+			return fmt.Errorf("must be host:port: %w", err)
+		}
+		if _, err := strconv.ParseUint(port, 10, 16); err != nil {
+			return fmt.Errorf("must have a valid port, got %q", port)
+		}
+
+	default:
+		return fmt.Errorf("unknown validation rule %q", rule)
+	}
+
+	return nil
+}
+
+var durationType = reflect.TypeOf(time.Duration(0))
+
+func validateBound(value reflect.Value, rule string, arg string) error {
+	check := func(ok bool, got any) error {
+		if ok {
+			return nil
+		}
+
+		bound := "least"
+		if rule == "max" {
+			bound = "most"
+		}
+		return fmt.Errorf("must be at %s %s, got %v", bound, arg, got)
+	}
+
+	if !value.IsValid() {
+		return nil
+	}
+
+	if value.Type() == durationType {
+		limit, err := time.ParseDuration(arg)
+		if err != nil {
+			return fmt.Errorf("bad %s rule: %w", rule, err)
+		}
+		d := time.Duration(value.Int())
+		return check(compare(rule, float64(d), float64(limit)), d)
+	}
+
+	var got float64
+
+	switch value.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		got = float64(value.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		got = float64(value.Uint())
+	case reflect.Float32, reflect.Float64:
+		got = value.Float()
+	case reflect.String, reflect.Slice, reflect.Map:
+		limit, err := strconv.Atoi(arg)
+		if err != nil {
+			return fmt.Errorf("bad %s rule: %w", rule, err)
+		}
+		return check(compare(rule, float64(value.Len()), float64(limit)), fmt.Sprintf("length %d", value.Len()))
+	default:
+		return fmt.Errorf("%s rule not supported for %s", rule, value.Type())
+	}
+
+	limit, err := strconv.ParseFloat(arg, 64)
+	if err != nil {
+		return fmt.Errorf("bad %s rule: %w", rule, err)
+	}
+
+	return check(compare(rule, got, limit), value.Interface())
+}
+
+func compare(rule string, got float64, limit float64) bool {
+	if rule == "min" {
+		return got >= limit
+	}
+	return got <= limit
+}
@@ -0,0 +1,120 @@
+package uconfig_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/omeid/uconfig"
+	"github.com/omeid/uconfig/plugins/defaults"
+	"github.com/omeid/uconfig/plugins/env"
+	"github.com/omeid/uconfig/plugins/flag"
+)
+
+type fValidate struct {
+	Port     int           `default:"8080" validate:"min=1,max=65535"`
+	Level    string        `default:"info" validate:"oneof=debug|info|warn"`
+	Name     string        `default:"app-1" validate:"nonzero,regexp=^[a-z]+-[0-9]+$"`
+	Endpoint string        `validate:"url"`
+	Listen   string        `default:"localhost:80" validate:"hostport"`
+	Timeout  time.Duration `default:"5s" validate:"min=1s,max=1m"`
+	Hosts    []string      `default:"a,b" validate:"min=1,max=3"`
+}
+
+func TestValidate(t *testing.T) {
+	conf := uconfig.New[fValidate](defaults.New())
+
+	_, err := conf.Parse()
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestValidateAllViolations(t *testing.T) {
+	args := []string{
+		"-port=70000",
+		"-level=trace",
+		"-name=",
+		"-endpoint=not a url",
+		"-listen=localhost",
+		"-timeout=2m",
+		"-hosts=a,b,c,d",
+	}
+
+	conf := uconfig.New[fValidate](
+		defaults.New(),
+		env.New(),
+		flag.New("testing", flag.ContinueOnError, args),
+	)
+
+	_, err := conf.Parse()
+	if err == nil {
+		t.Fatal("expected validation error, got nil")
+	}
+
+	expect := []string{
+		`Port (-port, PORT): must be at most 65535, got 70000`,
+		`Level (-level, LEVEL): must be one of debug, info, warn, got "trace"`,
+		`Name (-name, NAME): must be set`,
+		`Endpoint (-endpoint, ENDPOINT): must be an absolute url, got "not a url"`,
+		`Listen (-listen, LISTEN): must be host:port: address localhost: missing port in address`,
+		`Timeout (-timeout, TIMEOUT): must be at most 1m, got 2m0s`,
+		`Hosts (-hosts, HOSTS): must be at most 3, got length 4`,
+	}
+
+	if diff := cmp.Diff(expect, strings.Split(err.Error(), "\n")); diff != "" {
+		t.Error(diff)
+	}
+}
+
+func TestValidateUnknownRule(t *testing.T) {
+	type Config struct {
+		Name string `default:"x" validate:"shiny"`
+	}
+
+	conf := uconfig.New[Config](defaults.New())
+
+	_, err := conf.Parse()
+	if err == nil {
+		t.Fatal("expected error for unknown rule, got nil")
+	}
+
+	expect := `Name (validate): unknown validation rule "shiny"`
+	if err.Error() != expect {
+		t.Errorf("expected (%s) but got (%s)", expect, err)
+	}
+}
+
+func TestValidateUsage(t *testing.T) {
+	type Config struct {
+		Port int `validate:"min=1" usage:"port to listen on"`
+		Name string
+	}
+
+	var stdout bytes.Buffer
+	uconfig.UsageOutput = &stdout
+
+	conf := uconfig.New[Config](flag.New("testing", flag.ContinueOnError, nil))
+	_, err := conf.Parse()
+	if err == nil {
+		t.Fatal("expected validation error, got nil")
+	}
+
+	conf.Usage()
+
+	expect := `Usage:
+    uconfig.test [flags] [command]
+
+Configurations:
+FIELD    FLAG     VALIDATE    USAGE
+-----    -----    --------    -----
+Port     -port    min=1       port to listen on
+Name     -name                
+`
+
+	if diff := cmp.Diff(expect, stdout.String()); diff != "" {
+		t.Error(diff)
+	}
+}
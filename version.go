@@ -0,0 +1,99 @@
+package uconfig
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"runtime/debug"
+	"text/tabwriter"
+
+	"github.com/omeid/uconfig/plugins"
+)
+
+// ErrVersion is returned when the user requests the version of the
+// program (e.g. -version flag), which Run prints before exiting.
+var ErrVersion = plugins.ErrVersion
+
+// Version returns a plugin that enables the version request of the
+// plugins that support it, like the -version flag, which makes Run
+// print the version with the module, VCS revision, and Go version from
+// the build info. The version is taken from the build info when empty.
+func Version(version string) plugins.Plugin {
+	return &versionPlugin{
+		extension: extend(func(v plugins.Versioner) { v.SetVersion(version) }),
+		version:   version,
+	}
+}
+
+// versionPlugin keeps the version for WriteVersion.
+type versionPlugin struct {
+	*extension[plugins.Versioner]
+	version string
+}
+
+// version returns the version given to the Version plugin, if any.
+func (c *config[C]) version() string {
+	for _, p := range c.plugins {
+		if v, ok := p.(*versionPlugin); ok {
+			return v.version
+		}
+	}
+	return ""
+}
+
+func (c *config[C]) WriteVersion(w io.Writer) error {
+	c.mu.Lock()
+	name := c.usageOpts.Name
+	c.mu.Unlock()
+
+	if name == "" {
+		name = path.Base(os.Args[0])
+	}
+
+	info, _ := debug.ReadBuildInfo()
+	return writeVersion(w, name, c.version(), info)
+}
+
+// writeVersion writes the name and version of the program, followed
+// by what the build info has to say about it, if anything.
+func writeVersion(w io.Writer, name string, version string, info *debug.BuildInfo) error {
+	if version == "" && info != nil {
+		version = info.Main.Version
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 0, 1, ' ', 0)
+
+	if version == "" {
+		_, _ = fmt.Fprintln(tw, name)
+	} else {
+		_, _ = fmt.Fprintf(tw, "%s %s\n", name, version)
+	}
+
+	if info == nil {
+		return tw.Flush()
+	}
+
+	settings := map[string]string{}
+	for _, setting := range info.Settings {
+		settings[setting.Key] = setting.Value
+	}
+
+	revision := settings["vcs.revision"]
+	if revision != "" && settings["vcs.modified"] == "true" {
+		revision += " (dirty)"
+	}
+
+	for _, row := range [][2]string{
+		{"module:", info.Main.Path},
+		{"revision:", revision},
+		{"time:", settings["vcs.time"]},
+		{"go:", info.GoVersion},
+	} {
+		if row[1] != "" {
+			_, _ = fmt.Fprintf(tw, "%s\t%s\n", row[0], row[1])
+		}
+	}
+
+	return tw.Flush()
+}
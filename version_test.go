@@ -0,0 +1,74 @@
+package uconfig_test
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/omeid/uconfig"
+	"github.com/omeid/uconfig/plugins/flag"
+)
+
+func TestVersion(t *testing.T) {
+	type Config struct {
+		Debug bool
+	}
+
+	conf := uconfig.New[Config](
+		uconfig.Version("1.2.3"),
+		flag.New("testing", flag.ContinueOnError, []string{"-debug", "-version"}),
+	)
+
+	_, err := conf.Parse()
+	if !errors.Is(err, uconfig.ErrVersion) {
+		t.Fatalf("expected ErrVersion, got %v", err)
+	}
+
+	var out bytes.Buffer
+	err = conf.WriteVersion(&out)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !strings.HasPrefix(out.String(), "uconfig.test 1.2.3\n") {
+		t.Errorf("expected the name and version first, got:\n%s", out.String())
+	}
+
+	if !strings.Contains(out.String(), "\ngo:") {
+		t.Errorf("expected the Go version from the build info, got:\n%s", out.String())
+	}
+}
+
+func TestVersionDisabled(t *testing.T) {
+	type Config struct {
+		Debug bool
+	}
+
+	conf := uconfig.New[Config](flag.New("testing", flag.ContinueOnError, []string{"-version"}))
+
+	_, err := conf.Parse()
+	if err == nil || errors.Is(err, uconfig.ErrVersion) {
+		t.Fatalf("expected -version to be undefined, got %v", err)
+	}
+}
+
+func TestVersionField(t *testing.T) {
+	type Config struct {
+		Version bool
+	}
+
+	conf := uconfig.New[Config](
+		uconfig.Version("1.2.3"),
+		flag.New("testing", flag.ContinueOnError, []string{"-version"}),
+	)
+
+	value, err := conf.Parse()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !value.Version {
+		t.Error("expected the field to take precedence over the version flag")
+	}
+}
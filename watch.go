@@ -2,16 +2,112 @@ package uconfig
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync/atomic"
+	"time"
 
+	"github.com/omeid/uconfig/flat"
 	"github.com/omeid/uconfig/plugins"
 )
 
+const (
+	reloadTag     = "reload"
+	reloadHot     = "hot"
+	reloadRestart = "restart"
+)
+
+func init() {
+	plugins.RegisterTag(reloadTag)
+}
+
+// ErrRestartRequired is returned by Watch when a field tagged with
+// reload:"restart" has changed and no OnRestartRequired is set.
+var ErrRestartRequired = errors.New("uconfig: restart required")
+
+// WatchOptions controls how WatchWith reloads the config, including
+// how it handles the fields tagged with reload:"hot" or reload:"restart".
+// Changes to fields without the reload tag always restart fn.
+type WatchOptions[C any] struct {
+	// OnHotReload is called instead of restarting fn when all the
+	// changed fields are tagged with reload:"hot", fn keeps running
+	// and it is up to OnHotReload to apply the new values.
+	// When nil, hot fields are handled like any other field.
+	OnHotReload func(old *C, new *C, changes []Change)
+
+	// OnRestartRequired is called with the changed fields when any of
+	// them is tagged with reload:"restart". The new config is discarded
+	// and fn keeps running with the current one, unless an error is
+	// returned, in which case Watch exits with it.
+	// When nil, Watch exits with ErrRestartRequired.
+	OnRestartRequired func(changes []Change) error
+
+	// OnError is called with the error of every failed re-parse,
+	// fn keeps running with the current config.
+	OnError func(err error)
+
+	// OnReload is called whenever a re-parsed config replaces the
+	// current one, before fn is restarted or OnHotReload is called.
+	OnReload func(old *C, new *C)
+
+	// Debounce delays a re-parse until the updaters have been quiet
+	// for this long, so that a burst of changes causes a single reload.
+	Debounce time.Duration
+
+	// MinInterval is the minimum time between two re-parses.
+	MinInterval time.Duration
+
+	// Stats, when set, counts the successful and failed re-parses.
+	Stats *WatchStats
+}
+
+// WatchStats counts the re-parses of a Watch, it is safe to
+// read while Watch is running.
+type WatchStats struct {
+	// Reloads is the number of successful re-parses.
+	Reloads atomic.Uint64
+	// Failures is the number of failed re-parses.
+	Failures atomic.Uint64
+}
+
+func (s *WatchStats) reloaded() {
+	if s != nil {
+		s.Reloads.Add(1)
+	}
+}
+
+func (s *WatchStats) failed() {
+	if s != nil {
+		s.Failures.Add(1)
+	}
+}
+
 func (c *config[C]) Watch(ctx context.Context, fn func(ctx context.Context, c *C) error) error {
-	conf, err := c.Parse()
+	return c.watch(ctx, WatchOptions[C]{}, false, func(ctx context.Context, _ *C, conf *C, _ []Change) error {
+		return fn(ctx, conf)
+	})
+}
+
+func (c *config[C]) WatchDiff(ctx context.Context, fn func(ctx context.Context, old *C, new *C, changes []Change) error) error {
+	return c.watch(ctx, WatchOptions[C]{}, true, fn)
+}
+
+func (c *config[C]) WatchWith(ctx context.Context, opts WatchOptions[C], fn func(ctx context.Context, old *C, new *C, changes []Change) error) error {
+	return c.watch(ctx, opts, true, fn)
+}
+
+func (c *config[C]) watch(ctx context.Context, opts WatchOptions[C], skipUnchanged bool, fn func(ctx context.Context, old *C, new *C, changes []Change) error) error {
+	conf, fields, err := c.parse(ctx)
 	if err != nil {
-		return err
+		return c.closeWith(err)
 	}
 
+	return c.closeWith(c.watchFrom(ctx, conf, fields, opts, skipUnchanged, fn))
+}
+
+// watchFrom is watch starting from an already parsed config.
+func (c *config[C]) watchFrom(ctx context.Context, conf *C, fields flat.Fields, opts WatchOptions[C], skipUnchanged bool, fn func(ctx context.Context, old *C, new *C, changes []Change) error) error {
 	// Collect updaters.
 	var updaters []plugins.Updater
 	for _, p := range c.plugins {
@@ -22,7 +118,7 @@ func (c *config[C]) Watch(ctx context.Context, fn func(ctx context.Context, c *C
 
 	// No updaters: call fn once.
 	if len(updaters) == 0 {
-		return fn(ctx, conf)
+		return fn(ctx, nil, conf, nil)
 	}
 
 	// Start persistent update watchers. Each goroutine loops calling
@@ -32,45 +128,149 @@ func (c *config[C]) Watch(ctx context.Context, fn func(ctx context.Context, c *C
 	defer watchCancel()
 	changed := startUpdaters(watchCtx, updaters)
 
+	var (
+		prev    *C
+		changes []Change
+		last    time.Time
+	)
+
+	// pending fires when a signalled change is due for a re-parse.
+	pending := time.NewTimer(time.Hour)
+	pending.Stop()
+	defer pending.Stop()
+
 	for {
 		// Run fn with a cancellable sub-context.
 		runCtx, runCancel := context.WithCancel(ctx)
 		fnDone := make(chan error, 1)
-		go func() { fnDone <- fn(runCtx, conf) }()
+		go func(prev, conf *C, changes []Change) {
+			fnDone <- fn(runCtx, prev, conf, changes)
+		}(prev, conf, changes)
 
-		select {
-		case <-changed:
-			// Source changed: stop fn, re-parse.
-			runCancel()
-			<-fnDone
+	reload:
+		for {
+			select {
+			case <-changed:
+				// Source changed: wait for it to settle and for the
+				// minimum interval since the last reload to pass.
+				delay := opts.Debounce
+				if wait := opts.MinInterval - time.Since(last); wait > delay {
+					delay = wait
+				}
+				resetTimer(pending, delay)
+				continue
 
-		case err := <-fnDone:
-			// fn returned on its own — exit Watch.
-			runCancel()
-			return err
+			case <-pending.C:
 
-		case <-ctx.Done():
-			runCancel()
-			<-fnDone
-			return ctx.Err()
-		}
+			case err := <-fnDone:
+				// fn returned on its own — exit Watch.
+				runCancel()
+				return err
 
-		// Re-parse config.
-		newConf, err := c.Parse()
-		if err != nil {
-			// Bad config — wait for next change and retry.
-			select {
-			case <-changed:
 			case <-ctx.Done():
+				runCancel()
+				<-fnDone
+				return ctx.Err()
+			}
+
+			// Re-parse before touching fn, so that a bad
+			// config leaves fn running with the current one.
+			last = time.Now()
+			newConf, newFields, err := c.parse(ctx)
+			if err != nil && ctx.Err() != nil {
+				// interrupted by shutdown.
+				runCancel()
+				<-fnDone
 				return ctx.Err()
 			}
-			newConf, err = c.Parse()
 			if err != nil {
-				continue // keep retrying
+				// Bad config — wait for next change and retry.
+				opts.Stats.failed()
+				if opts.OnError != nil {
+					opts.OnError(err)
+				}
+				continue
+			}
+			opts.Stats.reloaded()
+
+			diff := diffFields(fields, newFields)
+			if skipUnchanged && len(diff) == 0 {
+				continue
 			}
+
+			if restart := reloadFields(newFields, diff, reloadRestart); len(restart) > 0 {
+				// keep the config fn is running with.
+				c.publish(conf, fields)
+
+				err := restartRequired(opts, diff)
+				if err != nil {
+					runCancel()
+					<-fnDone
+					return err
+				}
+				continue
+			}
+
+			if opts.OnReload != nil {
+				opts.OnReload(conf, newConf)
+			}
+
+			if opts.OnHotReload != nil && len(diff) > 0 &&
+				len(reloadFields(newFields, diff, reloadHot)) == len(diff) {
+				opts.OnHotReload(conf, newConf, diff)
+				conf, fields = newConf, newFields
+				continue
+			}
+
+			runCancel()
+			<-fnDone
+
+			prev, conf, fields, changes = conf, newConf, newFields, diff
+			break reload
+		}
+	}
+}
+
+// resetTimer resets t to fire after d, discarding any pending fire.
+func resetTimer(t *time.Timer, d time.Duration) {
+	if !t.Stop() {
+		select {
+		case <-t.C:
+		default:
+		}
+	}
+	t.Reset(d)
+}
+
+func restartRequired[C any](opts WatchOptions[C], changes []Change) error {
+	if opts.OnRestartRequired != nil {
+		return opts.OnRestartRequired(changes)
+	}
+
+	names := make([]string, len(changes))
+	for i, change := range changes {
+		names[i] = change.Field
+	}
+
+	return fmt.Errorf("%w: %s changed", ErrRestartRequired, strings.Join(names, ", "))
+}
+
+// reloadFields returns the changes to fields with the given reload tag.
+func reloadFields(fs flat.Fields, changes []Change, reload string) []Change {
+	tags := make(map[string]string, len(fs))
+	for _, f := range fs {
+		name, _ := f.Name("")
+		tags[name], _ = f.Tag(reloadTag)
+	}
+
+	var matched []Change
+	for _, change := range changes {
+		if tags[change.Field] == reload {
+			matched = append(matched, change)
 		}
-		conf = newConf
 	}
+
+	return matched
 }
 
 // startUpdaters launches a goroutine per Updater that loops calling
@@ -0,0 +1,476 @@
+package uconfig_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/omeid/uconfig"
+	"github.com/omeid/uconfig/flat"
+	"github.com/omeid/uconfig/plugins"
+)
+
+// sourceUpdater is a fake Visitor+Updater that sets fields from
+// a map of values which can be changed between parses.
+type sourceUpdater struct {
+	ch chan struct{}
+
+	mu     sync.Mutex
+	values map[string]string
+	fields flat.Fields
+}
+
+func newSourceUpdater(values map[string]string) *sourceUpdater {
+	return &sourceUpdater{ch: make(chan struct{}, 1), values: values}
+}
+
+func (u *sourceUpdater) set(name string, value string) {
+	u.mu.Lock()
+	u.values[name] = value
+	u.mu.Unlock()
+
+	u.ch <- struct{}{}
+}
+
+func (u *sourceUpdater) Visit(fs flat.Fields) error {
+	u.fields = fs
+	return nil
+}
+
+func (u *sourceUpdater) Parse() error {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	for _, f := range u.fields {
+		name, _ := f.Name("")
+		value, ok := u.values[name]
+		if !ok {
+			continue
+		}
+
+		err := f.SetFrom(flat.Source{Plugin: "test", Key: name, Value: value})
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (u *sourceUpdater) Updated(ctx context.Context) bool {
+	select {
+	case <-u.ch:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+var _ plugins.Updater = (*sourceUpdater)(nil)
+
+type watchConfig struct {
+	Level    string
+	Port     int
+	Password string `secret:""`
+}
+
+type watchCall struct {
+	old     *watchConfig
+	new     *watchConfig
+	changes []uconfig.Change
+}
+
+func TestWatchDiff(t *testing.T) {
+	source := newSourceUpdater(map[string]string{
+		"Level":    "info",
+		"Port":     "80",
+		"Password": "hunter2",
+	})
+
+	conf := uconfig.New[watchConfig](source)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	calls := make(chan watchCall, 5)
+
+	go conf.WatchDiff(ctx, func(ctx context.Context, old, new *watchConfig, changes []uconfig.Change) error {
+		calls <- watchCall{old, new, changes}
+		<-ctx.Done()
+		return nil
+	})
+
+	next := func() watchCall {
+		select {
+		case call := <-calls:
+			return call
+		case <-time.After(2 * time.Second):
+			t.Fatal("timeout waiting for fn")
+		}
+		return watchCall{}
+	}
+
+	first := next()
+	if first.old != nil || first.changes != nil {
+		t.Fatalf("expected no old config or changes on first call, got %v, %v", first.old, first.changes)
+	}
+
+	source.mu.Lock()
+	source.values["Port"] = "8080"
+	source.values["Password"] = "hunter3"
+	source.mu.Unlock()
+	source.ch <- struct{}{}
+
+	second := next()
+
+	if second.old != first.new {
+		t.Error("expected old to be the previous config")
+	}
+
+	expect := []uconfig.Change{
+		{Field: "Port", Old: "80", New: "8080"},
+		{Field: "Password", Old: "******", New: "******"},
+	}
+
+	if diff := cmp.Diff(expect, second.changes); diff != "" {
+		t.Error(diff)
+	}
+
+	// a reload without any change must not restart fn.
+	source.set("Level", "info")
+
+	select {
+	case call := <-calls:
+		t.Fatalf("fn restarted without any change: %v", call.changes)
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	source.set("Level", "debug")
+
+	third := next()
+	expect = []uconfig.Change{{Field: "Level", Old: "info", New: "debug"}}
+	if diff := cmp.Diff(expect, third.changes); diff != "" {
+		t.Error(diff)
+	}
+}
+
+func TestWatchKeepsRunningOnBadReload(t *testing.T) {
+	source := newSourceUpdater(map[string]string{"Port": "80"})
+
+	conf := uconfig.New[watchConfig](source)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	started := make(chan *watchConfig, 5)
+	stopped := make(chan struct{}, 5)
+
+	go conf.Watch(ctx, func(ctx context.Context, c *watchConfig) error {
+		started <- c
+		<-ctx.Done()
+		stopped <- struct{}{}
+		return nil
+	})
+
+	first := <-started
+
+	source.set("Port", "not-a-number")
+
+	select {
+	case <-stopped:
+		t.Fatal("fn stopped for a bad reload")
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	source.set("Port", "8080")
+
+	select {
+	case c := <-started:
+		if c.Port != 8080 {
+			t.Errorf("expected 8080, got %d", c.Port)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("timeout waiting for reload")
+	}
+
+	if first.Port != 80 {
+		t.Errorf("expected the first config to be untouched, got %d", first.Port)
+	}
+}
+
+type reloadConfig struct {
+	Listen string `reload:"restart"`
+	Level  string `reload:"hot"`
+	Limit  int    `reload:"hot"`
+	Name   string
+}
+
+func TestWatchWithHotReload(t *testing.T) {
+	source := newSourceUpdater(map[string]string{"Level": "info", "Limit": "10"})
+
+	conf := uconfig.New[reloadConfig](source)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	started := make(chan *reloadConfig, 5)
+	hot := make(chan []uconfig.Change, 5)
+
+	opts := uconfig.WatchOptions[reloadConfig]{
+		OnHotReload: func(old, new *reloadConfig, changes []uconfig.Change) {
+			hot <- changes
+		},
+	}
+
+	go conf.WatchWith(ctx, opts, func(ctx context.Context, old, new *reloadConfig, changes []uconfig.Change) error {
+		started <- new
+		<-ctx.Done()
+		return nil
+	})
+
+	<-started
+
+	source.set("Level", "debug")
+
+	select {
+	case changes := <-hot:
+		expect := []uconfig.Change{{Field: "Level", Old: "info", New: "debug"}}
+		if diff := cmp.Diff(expect, changes); diff != "" {
+			t.Error(diff)
+		}
+	case <-started:
+		t.Fatal("fn restarted for a hot field")
+	case <-time.After(2 * time.Second):
+		t.Fatal("timeout waiting for hot reload")
+	}
+
+	// a field without reload tag still restarts fn.
+	source.set("Name", "new-name")
+
+	select {
+	case c := <-started:
+		if c.Name != "new-name" || c.Level != "debug" {
+			t.Errorf("unexpected config after restart: %+v", c)
+		}
+	case <-hot:
+		t.Fatal("hot reload for a field without reload tag")
+	case <-time.After(2 * time.Second):
+		t.Fatal("timeout waiting for restart")
+	}
+}
+
+func TestWatchWithRestartRequired(t *testing.T) {
+	source := newSourceUpdater(map[string]string{"Listen": ":80"})
+
+	conf := uconfig.New[reloadConfig](source)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	started := make(chan *reloadConfig, 5)
+	restarts := make(chan []uconfig.Change, 5)
+
+	opts := uconfig.WatchOptions[reloadConfig]{
+		OnRestartRequired: func(changes []uconfig.Change) error {
+			restarts <- changes
+			return nil
+		},
+	}
+
+	go conf.WatchWith(ctx, opts, func(ctx context.Context, old, new *reloadConfig, changes []uconfig.Change) error {
+		started <- new
+		<-ctx.Done()
+		return nil
+	})
+
+	<-started
+
+	source.set("Listen", ":8080")
+
+	select {
+	case changes := <-restarts:
+		expect := []uconfig.Change{{Field: "Listen", Old: ":80", New: ":8080"}}
+		if diff := cmp.Diff(expect, changes); diff != "" {
+			t.Error(diff)
+		}
+	case <-started:
+		t.Fatal("fn restarted for a restart-only field")
+	case <-time.After(2 * time.Second):
+		t.Fatal("timeout waiting for restart notification")
+	}
+
+	if listen := conf.Sources()["Listen"]; listen[len(listen)-1].Value != ":80" {
+		t.Errorf("expected the current snapshot to be kept, got %v", listen)
+	}
+}
+
+func TestWatchRestartRequiredError(t *testing.T) {
+	source := newSourceUpdater(map[string]string{"Listen": ":80"})
+
+	conf := uconfig.New[reloadConfig](source)
+
+	started := make(chan struct{}, 5)
+	done := make(chan error, 1)
+
+	go func() {
+		done <- conf.Watch(context.Background(), func(ctx context.Context, c *reloadConfig) error {
+			started <- struct{}{}
+			<-ctx.Done()
+			return nil
+		})
+	}()
+
+	<-started
+
+	source.set("Listen", ":8080")
+
+	select {
+	case err := <-done:
+		if !errors.Is(err, uconfig.ErrRestartRequired) {
+			t.Fatalf("expected ErrRestartRequired, got %v", err)
+		}
+		expect := "uconfig: restart required: Listen changed"
+		if err.Error() != expect {
+			t.Errorf("expected (%s) but got (%s)", expect, err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("timeout waiting for Watch to exit")
+	}
+}
+
+func TestWatchWithErrorsAndStats(t *testing.T) {
+	source := newSourceUpdater(map[string]string{"Port": "80"})
+
+	conf := uconfig.New[watchConfig](source)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var stats uconfig.WatchStats
+	errs := make(chan error, 5)
+	reloads := make(chan [2]*watchConfig, 5)
+
+	opts := uconfig.WatchOptions[watchConfig]{
+		OnError:  func(err error) { errs <- err },
+		OnReload: func(old, new *watchConfig) { reloads <- [2]*watchConfig{old, new} },
+		Stats:    &stats,
+	}
+
+	started := make(chan *watchConfig, 5)
+	go conf.WatchWith(ctx, opts, func(ctx context.Context, old, new *watchConfig, changes []uconfig.Change) error {
+		started <- new
+		<-ctx.Done()
+		return nil
+	})
+
+	first := <-started
+
+	source.set("Port", "not-a-number")
+
+	select {
+	case err := <-errs:
+		expect := `Port (Port): strconv.ParseInt: parsing "not-a-number": invalid syntax`
+		if err.Error() != expect {
+			t.Errorf("expected (%s) but got (%s)", expect, err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("timeout waiting for OnError")
+	}
+
+	source.set("Port", "8080")
+
+	select {
+	case reload := <-reloads:
+		if reload[0] != first || reload[1].Port != 8080 {
+			t.Errorf("unexpected reload: %+v -> %+v", reload[0], reload[1])
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("timeout waiting for OnReload")
+	}
+
+	<-started
+
+	if reloads, failures := stats.Reloads.Load(), stats.Failures.Load(); reloads != 1 || failures != 1 {
+		t.Errorf("expected 1 reload and 1 failure, got %d and %d", reloads, failures)
+	}
+}
+
+func TestWatchWithDebounce(t *testing.T) {
+	source := newSourceUpdater(map[string]string{"Port": "80"})
+
+	conf := uconfig.New[watchConfig](source)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var stats uconfig.WatchStats
+	opts := uconfig.WatchOptions[watchConfig]{
+		Debounce: 100 * time.Millisecond,
+		Stats:    &stats,
+	}
+
+	started := make(chan *watchConfig, 5)
+	go conf.WatchWith(ctx, opts, func(ctx context.Context, old, new *watchConfig, changes []uconfig.Change) error {
+		started <- new
+		<-ctx.Done()
+		return nil
+	})
+
+	<-started
+
+	for port := 8081; port <= 8085; port++ {
+		source.set("Port", fmt.Sprint(port))
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	select {
+	case c := <-started:
+		if c.Port != 8085 {
+			t.Errorf("expected the last value, got %d", c.Port)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("timeout waiting for reload")
+	}
+
+	if reloads := stats.Reloads.Load(); reloads != 1 {
+		t.Errorf("expected the burst to cause 1 reload, got %d", reloads)
+	}
+}
+
+func TestWatchWithMinInterval(t *testing.T) {
+	source := newSourceUpdater(map[string]string{"Port": "80"})
+
+	conf := uconfig.New[watchConfig](source)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	opts := uconfig.WatchOptions[watchConfig]{MinInterval: 200 * time.Millisecond}
+
+	started := make(chan time.Time, 5)
+	go conf.WatchWith(ctx, opts, func(ctx context.Context, old, new *watchConfig, changes []uconfig.Change) error {
+		started <- time.Now()
+		<-ctx.Done()
+		return nil
+	})
+
+	<-started
+
+	source.set("Port", "8081")
+	first := <-started
+
+	source.set("Port", "8082")
+
+	select {
+	case second := <-started:
+		if gap := second.Sub(first); gap < 150*time.Millisecond {
+			t.Errorf("expected reloads to be at least 200ms apart, got %s", gap)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("timeout waiting for reload")
+	}
+}